@@ -2,6 +2,7 @@ package alexa
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
@@ -14,6 +15,7 @@ const sdkVersion = "1.0"
 const launchRequestName = "LaunchRequest"
 const intentRequestName = "IntentRequest"
 const sessionEndedRequestName = "SessionEndedRequest"
+const sessionResumedRequestName = "SessionResumedRequest"
 
 var timestampTolerance = 150
 
@@ -27,6 +29,33 @@ type Alexa struct {
 	RequestHandler      RequestHandler
 	IgnoreApplicationID bool
 	IgnoreTimestamp     bool
+
+	// PostResponseHooks are called with the final response once it has been
+	// computed. See PostResponseHook.
+	PostResponseHooks []PostResponseHook
+	// PostResponseHookTimeout bounds the hooks, or the deadline of the
+	// context if it is earlier. Default value is 500 milliseconds.
+	PostResponseHookTimeout time.Duration
+
+	// SensitiveSlots are masked in SDK output. See SensitiveSlots.
+	SensitiveSlots *SensitiveSlots
+
+	// ServiceClientFactory creates the clients returned by
+	// ServiceClientFromContext. Default value is NewServiceClient.
+	ServiceClientFactory func(*Context) *ServiceClient
+
+	// RequestInterceptors run, in order, before the RequestHandler. See
+	// RequestInterceptor.
+	RequestInterceptors []RequestInterceptor
+
+	// ResponseValidators check every response before it is returned. See
+	// ResponseValidator.
+	ResponseValidators []ResponseValidator
+
+	// Tenants are the brands of a white-label skill. When set, requests are
+	// accepted from the application ID of any tenant, and the Tenant of the
+	// request is available from TenantFromContext.
+	Tenants []*Tenant
 }
 
 // RequestHandler defines the interface that must be implemented to handle
@@ -38,6 +67,13 @@ type RequestHandler interface {
 	OnSessionEnded(context.Context, *Request, *Session, *Context, *Response) error
 }
 
+// SessionResumedHandler is implemented by a RequestHandler that handles
+// SessionResumedRequests, sent when a Connections.StartConnection task
+// returns to the skill. Without it they are ignored.
+type SessionResumedHandler interface {
+	OnSessionResumed(context.Context, *Request, *Session, *Context, *Response) error
+}
+
 // RequestEnvelope contains the data passed from Alexa to the request handler.
 type RequestEnvelope struct {
 	Version string   `json:"version"`
@@ -70,6 +106,8 @@ type Context struct {
 			SupportedInterfaces struct {
 				AudioPlayer struct {
 				} `json:"AudioPlayer"`
+				Display              *DisplayInterface `json:"Display,omitempty"`
+				AlexaPresentationAPL *APLInterface     `json:"Alexa.Presentation.APL,omitempty"`
 			} `json:"supportedInterfaces"`
 		} `json:"device"`
 		Application struct {
@@ -82,8 +120,9 @@ type Context struct {
 				ConsentToken string `json:"consentToken"`
 			} `json:"permissions"`
 		} `json:"user"`
-		APIEndpoint    string `json:"apiEndpoint"`
-		APIAccessToken string `json:"apiAccessToken"`
+		Person         *Person `json:"person,omitempty"`
+		APIEndpoint    string  `json:"apiEndpoint"`
+		APIAccessToken string  `json:"apiAccessToken"`
 	} `json:"System"`
 	AudioPlayer struct {
 		PlayerActivity       string `json:"playerActivity"`
@@ -92,6 +131,26 @@ type Context struct {
 	} `json:"AudioPlayer"`
 }
 
+// Person identifies the speaker when Alexa recognized their voice.
+type Person struct {
+	PersonID    string `json:"personId"`
+	AccessToken string `json:"accessToken,omitempty"`
+}
+
+// DisplayInterface is present in SupportedInterfaces when the device has a screen.
+type DisplayInterface struct {
+	TemplateVersion string `json:"templateVersion,omitempty"`
+	MarkupVersion   string `json:"markupVersion,omitempty"`
+}
+
+// APLInterface is present in SupportedInterfaces when the device supports the
+// Alexa Presentation Language.
+type APLInterface struct {
+	Runtime struct {
+		MaxVersion string `json:"maxVersion,omitempty"`
+	} `json:"runtime"`
+}
+
 // Request contains the data in the request within the main request.
 type Request struct {
 	Locale      string `json:"locale"`
@@ -101,6 +160,19 @@ type Request struct {
 	DialogState string `json:"dialogState"`
 	Intent      Intent `json:"intent"`
 	Name        string `json:"name"`
+	Cause       *Cause `json:"cause,omitempty"`
+}
+
+// Cause is why a SessionResumedRequest was sent, such as the completion of a
+// Connections.StartConnection task.
+type Cause struct {
+	Type   string `json:"type"`
+	Token  string `json:"token"`
+	Status struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"status"`
+	Result json.RawMessage `json:"result,omitempty"`
 }
 
 // Intent contains the data about the Alexa Intent requested.
@@ -178,6 +250,8 @@ type Card struct {
 	Content string `json:"content,omitempty"`
 	Text    string `json:"text,omitempty"`
 	Image   *Image `json:"image,omitempty"`
+	// Permissions are the scopes asked for by an AskForPermissionsConsent card.
+	Permissions []string `json:"permissions,omitempty"`
 }
 
 // Image provides URL(s) to the image to display in resposne to the request.
@@ -218,33 +292,83 @@ type DialogDirective struct {
 	UpdatedIntent *Intent `json:"updatedIntent,omitempty"`
 }
 
+// ConnectionsDirective sends a request to a connection, such as the Buy, Upsell
+// and Cancel tasks of in-skill purchasing, or starts a task, such as adding
+// products to the Amazon shopping cart.
+type ConnectionsDirective struct {
+	Type    string                 `json:"type"`
+	Name    string                 `json:"name,omitempty"`
+	Payload map[string]interface{} `json:"payload,omitempty"`
+	// URI and Input are used by Connections.StartConnection.
+	URI   string      `json:"uri,omitempty"`
+	Input interface{} `json:"input,omitempty"`
+	Token string      `json:"token,omitempty"`
+}
+
 // ProcessRequest handles a request passed from Alexa
+//
+// The ctx passed to the RequestHandler carries the request and per-request
+// helpers. See RequestFromContext and LoggerFromContext.
+//
+// Any PostResponseHooks are run before ProcessRequest returns, so on Lambda
+// they delay the response to Alexa. They are bounded by
+// PostResponseHookTimeout, and by the deadline of ctx.
 func (alexa *Alexa) ProcessRequest(ctx context.Context, requestEnv *RequestEnvelope) (*ResponseEnvelope, error) {
+	ctx, responseEnv, err := alexa.processRequest(ctx, requestEnv)
+	if err != nil {
+		return nil, err
+	}
+
+	alexa.runPostResponseHooks(ctx, requestEnv, responseEnv)
+
+	return responseEnv, nil
+}
+
+// processRequest returns the response, and ctx as enriched by the request
+// interceptors.
+func (alexa *Alexa) processRequest(ctx context.Context, requestEnv *RequestEnvelope) (context.Context, *ResponseEnvelope, error) {
 	if requestEnv == nil {
-		return nil, ErrRequestEnvelopeNil
+		return ctx, nil, ErrRequestEnvelopeNil
 	}
 
 	if !alexa.IgnoreApplicationID {
 		err := alexa.verifyApplicationID(requestEnv)
 		if err != nil {
-			return nil, err
+			return ctx, nil, err
 		}
 	}
 	if !alexa.IgnoreTimestamp {
 		err := alexa.verifyTimestamp(requestEnv)
 		if err != nil {
-			return nil, err
+			return ctx, nil, err
 		}
 	} else {
 		log.Println("Ignoring timestamp verification.")
 	}
 
+	ctx, err := alexa.resolveTenant(ctx, requestEnv)
+	if err != nil {
+		return ctx, nil, err
+	}
+
 	request := requestEnv.Request
+	if requestEnv.Session == nil {
+		requestEnv.Session = &Session{}
+	}
 	session := requestEnv.Session
 	if session.Attributes.String == nil {
 		session.Attributes.String = make(map[string]interface{})
 	}
 	context := requestEnv.Context
+	ctx = alexa.withServices(ctx, requestEnv)
+
+	for _, interceptor := range alexa.RequestInterceptors {
+		ctx, err = interceptor.InterceptRequest(ctx, requestEnv)
+		if err != nil {
+			log.Println("Error running request interceptor.", err.Error())
+			return ctx, nil, err
+		}
+	}
 
 	responseEnv := &ResponseEnvelope{}
 	responseEnv.Version = sdkVersion
@@ -252,13 +376,14 @@ func (alexa *Alexa) ProcessRequest(ctx context.Context, requestEnv *RequestEnvel
 	responseEnv.Response.ShouldSessionEnd = true // Set default value.
 
 	response := responseEnv.Response
+	mask := alexa.SensitiveSlots.masker(&request.Intent)
 
 	// If it is a new session, invoke onSessionStarted
 	if session.New {
 		err := alexa.RequestHandler.OnSessionStarted(ctx, request, session, context, response)
 		if err != nil {
-			log.Println("Error handling OnSessionStarted.", err.Error())
-			return nil, err
+			log.Println("Error handling OnSessionStarted.", mask.text(err.Error()))
+			return ctx, nil, err
 		}
 	}
 
@@ -266,31 +391,48 @@ func (alexa *Alexa) ProcessRequest(ctx context.Context, requestEnv *RequestEnvel
 	case launchRequestName:
 		err := alexa.RequestHandler.OnLaunch(ctx, request, session, context, response)
 		if err != nil {
-			log.Println("Error handling OnLaunch.", err.Error())
-			return nil, err
+			log.Println("Error handling OnLaunch.", mask.text(err.Error()))
+			return ctx, nil, err
 		}
 	case intentRequestName:
 		err := alexa.RequestHandler.OnIntent(ctx, request, session, context, response)
 		if err != nil {
-			log.Println("Error handling OnIntent.", err.Error())
-			return nil, err
+			log.Println("Error handling OnIntent.", mask.text(err.Error()))
+			return ctx, nil, err
 		}
 	case sessionEndedRequestName:
 		err := alexa.RequestHandler.OnSessionEnded(ctx, request, session, context, response)
 		if err != nil {
-			log.Println("Error handling OnSessionEnded.", err.Error())
-			return nil, err
+			log.Println("Error handling OnSessionEnded.", mask.text(err.Error()))
+			return ctx, nil, err
+		}
+	case sessionResumedRequestName:
+		if h, ok := alexa.RequestHandler.(SessionResumedHandler); ok {
+			err := h.OnSessionResumed(ctx, request, session, context, response)
+			if err != nil {
+				log.Println("Error handling OnSessionResumed.", mask.text(err.Error()))
+				return ctx, nil, err
+			}
 		}
 	}
 
+	applyTenant(ctx, response)
+
 	// Copy Session Attributes into ResponseEnvelope
 	responseEnv.SessionAttributes = make(map[string]interface{})
 	for n, v := range session.Attributes.String {
-		fmt.Println("Setting ", n, "to", v)
+		fmt.Println("Setting ", n, "to", mask.attribute(n, v))
 		responseEnv.SessionAttributes[n] = v
 	}
 
-	return responseEnv, nil
+	for _, v := range alexa.ResponseValidators {
+		if err := v.ValidateResponse(ctx, requestEnv, responseEnv); err != nil {
+			log.Println("Error validating response.", mask.text(err.Error()))
+			return ctx, nil, err
+		}
+	}
+
+	return ctx, responseEnv, nil
 }
 
 // SetTimestampTolerance sets the maximum number of seconds to allow between
@@ -315,6 +457,12 @@ func (r *Response) SetLinkAccountCard() {
 	r.Card = &Card{Type: "LinkAccount"}
 }
 
+// SetAskForPermissionsConsentCard creates a new card asking the user to grant
+// permissions, such as read::alexa:device:all:address.
+func (r *Response) SetAskForPermissionsConsentCard(permissions []string) {
+	r.Card = &Card{Type: "AskForPermissionsConsent", Permissions: permissions}
+}
+
 // SetOutputText sets the OutputSpeech type to text and sets the value specified.
 func (r *Response) SetOutputText(text string) {
 	r.OutputSpeech = &OutputSpeech{Type: "PlainText", Text: text}
@@ -368,6 +516,31 @@ func (r *Response) AddDialogDirective(dialogType, slotToElicit, slotToConfirm st
 	r.Directives = append(r.Directives, d)
 }
 
+// AddConnectionsSendRequest adds a Connections.SendRequest directive to the
+// Response, such as the Buy request of in-skill purchasing.
+func (r *Response) AddConnectionsSendRequest(name string, payload map[string]interface{}, token string) {
+	d := ConnectionsDirective{
+		Type:    "Connections.SendRequest",
+		Name:    name,
+		Payload: payload,
+		Token:   token,
+	}
+	r.Directives = append(r.Directives, d)
+}
+
+// AddConnectionsStartConnection adds a Connections.StartConnection directive
+// to the Response, starting the task at uri with input. The session is resumed
+// with a SessionResumedRequest whose Cause has token.
+func (r *Response) AddConnectionsStartConnection(uri string, input interface{}, token string) {
+	d := ConnectionsDirective{
+		Type:  "Connections.StartConnection",
+		URI:   uri,
+		Input: input,
+		Token: token,
+	}
+	r.Directives = append(r.Directives, d)
+}
+
 // verifyApplicationId verifies that the ApplicationID sent in the request
 // matches the one configured for this skill.
 func (alexa *Alexa) verifyApplicationID(request *RequestEnvelope) error {
@@ -376,7 +549,13 @@ func (alexa *Alexa) verifyApplicationID(request *RequestEnvelope) error {
 	}
 
 	appID := alexa.ApplicationID
-	requestAppID := request.Session.Application.ApplicationID
+	requestAppID := requestApplicationID(request)
+	if len(alexa.Tenants) > 0 {
+		if requestAppID == "" || alexa.TenantFor(requestAppID) == nil {
+			return ErrUnknownTenant
+		}
+		return nil
+	}
 	if appID == "" {
 		return errors.New("application ID was set to an empty string")
 	}
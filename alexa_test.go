@@ -1,9 +1,16 @@
 package alexa
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -685,3 +692,185 @@ func (h *simpleDialogDirectiveResponseHandler) OnIntent(context context.Context,
 func (h *simpleDialogDirectiveResponseHandler) OnSessionEnded(context.Context, *Request, *Session, *Context, *Response) error {
 	return nil
 }
+
+func TestPostResponseHooks(t *testing.T) {
+	request := createRecipeRequest()
+
+	alexa := getAlexaWithHandler(&simpleResponseHandler{})
+	var mu sync.Mutex
+	var hookResponse *ResponseEnvelope
+	alexa.PostResponseHooks = []PostResponseHook{
+		func(ctx context.Context, requestEnv *RequestEnvelope, responseEnv *ResponseEnvelope) error {
+			mu.Lock()
+			defer mu.Unlock()
+			hookResponse = responseEnv
+			return nil
+		},
+		func(ctx context.Context, requestEnv *RequestEnvelope, responseEnv *ResponseEnvelope) error {
+			return errors.New("error in hook")
+		},
+		func(ctx context.Context, requestEnv *RequestEnvelope, responseEnv *ResponseEnvelope) error {
+			panic("panic in hook")
+		},
+	}
+
+	ctx := context.Background()
+	responseEnv, err := alexa.ProcessRequest(ctx, request)
+	if err != nil {
+		t.Fatal("Expected failing hooks not to affect the response but got error", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if hookResponse != responseEnv {
+		t.Error("Expected hook to be called with the final ResponseEnvelope.")
+	}
+}
+
+func TestPostResponseHooksBoundedByDeadline(t *testing.T) {
+	request := createRecipeRequest()
+
+	alexa := getAlexaWithHandler(&simpleResponseHandler{})
+	alexa.PostResponseHooks = []PostResponseHook{
+		func(ctx context.Context, requestEnv *RequestEnvelope, responseEnv *ResponseEnvelope) error {
+			time.Sleep(5 * time.Second)
+			return nil
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	start := time.Now()
+	_, err := alexa.ProcessRequest(ctx, request)
+	if err != nil {
+		t.Error("Error processing request. " + err.Error())
+	}
+	if time.Since(start) >= time.Second {
+		t.Error("Expected slow hook to be abandoned before the deadline but ProcessRequest took", time.Since(start))
+	}
+}
+
+func TestPostResponseHooksBoundedByTimeout(t *testing.T) {
+	alexa := getAlexaWithHandler(&simpleResponseHandler{})
+	alexa.PostResponseHookTimeout = 100 * time.Millisecond
+	alexa.RequestInterceptors = []RequestInterceptor{&greetingInterceptor{}}
+	greeting := make(chan interface{}, 1)
+	alexa.PostResponseHooks = []PostResponseHook{
+		func(ctx context.Context, requestEnv *RequestEnvelope, responseEnv *ResponseEnvelope) error {
+			greeting <- ctx.Value(greetingKey{})
+			<-ctx.Done()
+			return nil
+		},
+	}
+
+	// A Lambda function with a long timeout must still answer Alexa in time.
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	start := time.Now()
+	if _, err := alexa.ProcessRequest(ctx, createRecipeRequest()); err != nil {
+		t.Error("Error processing request. " + err.Error())
+	}
+	if time.Since(start) >= time.Second {
+		t.Error("Expected slow hook to be abandoned after PostResponseHookTimeout but ProcessRequest took", time.Since(start))
+	}
+	if g := <-greeting; g != "Hi" {
+		t.Error("Expected hooks to get the context of the interceptors but greeting was", g)
+	}
+}
+
+func TestServeHTTPRunsHooksAfterResponse(t *testing.T) {
+	alexa := getAlexaWithHandler(&simpleResponseHandler{})
+	release := make(chan struct{})
+	hookDone := make(chan struct{})
+	alexa.PostResponseHooks = []PostResponseHook{
+		func(ctx context.Context, requestEnv *RequestEnvelope, responseEnv *ResponseEnvelope) error {
+			<-release
+			close(hookDone)
+			return nil
+		},
+	}
+	server := httptest.NewServer(alexa)
+	defer server.Close()
+
+	body, _ := json.Marshal(createRecipeRequest())
+	resp, err := http.Post(server.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal("Error posting request. " + err.Error())
+	}
+	var responseEnv ResponseEnvelope
+	err = json.NewDecoder(resp.Body).Decode(&responseEnv)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatal("Error decoding response while hook was still running. " + err.Error())
+	}
+	if responseEnv.Response.OutputSpeech.Text != "Response Text" {
+		t.Errorf("Response Text should have been %s but was %s", "Response Text", responseEnv.Response.OutputSpeech.Text)
+	}
+
+	close(release)
+	select {
+	case <-hookDone:
+	case <-time.After(time.Second):
+		t.Error("Expected hook to run after the response was written.")
+	}
+}
+
+func TestServeHTTPBadRequest(t *testing.T) {
+	alexa := getAlexa()
+	rec := httptest.NewRecorder()
+	alexa.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader("not json")))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d but was %d", http.StatusBadRequest, rec.Code)
+	}
+}
+
+func TestServeHTTPRequestTooLarge(t *testing.T) {
+	alexa := getAlexa()
+	rec := httptest.NewRecorder()
+	body := `{"version":"` + strings.Repeat("x", maxRequestBytes) + `"}`
+	alexa.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected status %d but was %d", http.StatusRequestEntityTooLarge, rec.Code)
+	}
+}
+
+func TestHandlerMiddleware(t *testing.T) {
+	alexa := getAlexaWithHandler(&simpleResponseHandler{})
+	var calls []string
+	trace := func(name string) func(http.Handler) http.Handler {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requestEnv := RequestEnvelopeFromContext(r.Context())
+				body, _ := io.ReadAll(r.Body)
+				r.Body = io.NopCloser(bytes.NewReader(body))
+				calls = append(calls, name+" "+requestEnv.Request.RequestID+" "+strconv.FormatBool(len(body) > 0))
+				w.Header().Set("X-"+name, "1")
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/skills/recipes/", http.StripPrefix("/skills/recipes", alexa.Handler(trace("Auth"), trace("Tracing"))))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	body, _ := json.Marshal(createRecipeRequest())
+	resp, err := http.Post(server.URL+"/skills/recipes/", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal("Error posting request. " + err.Error())
+	}
+	var responseEnv ResponseEnvelope
+	json.NewDecoder(resp.Body).Decode(&responseEnv)
+	resp.Body.Close()
+
+	if responseEnv.Response == nil || responseEnv.Response.OutputSpeech.Text != "Response Text" {
+		t.Error("Expected skill response through the middleware but was", responseEnv.Response)
+	}
+	expected := []string{"Auth amzn1.echo-api.request.xyz789 true", "Tracing amzn1.echo-api.request.xyz789 true"}
+	if strings.Join(calls, ",") != strings.Join(expected, ",") {
+		t.Error("Expected middleware to run in order with the envelope and body but calls were", calls)
+	}
+	if resp.Header.Get("X-Auth") != "1" || resp.Header.Get("X-Tracing") != "1" {
+		t.Error("Expected middleware headers to be kept but were", resp.Header)
+	}
+}
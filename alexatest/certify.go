@@ -0,0 +1,137 @@
+package alexatest
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	alexa "github.com/ericdaugherty/alexa-skills-kit-golang"
+)
+
+// Violation describes a response that would fail skill certification.
+type Violation struct {
+	Locale   string
+	Device   string
+	Scenario string
+	Problem  string
+}
+
+func (v Violation) String() string {
+	return v.Locale + " (" + v.Device + ") " + v.Scenario + ": " + v.Problem
+}
+
+// requiredIntents must be present in every interaction model.
+var requiredIntents = []string{"AMAZON.StopIntent", "AMAZON.CancelIntent", "AMAZON.HelpIntent"}
+
+// Certify drives the scenarios that most often fail skill certification
+// through a.ProcessRequest, for every locale in models and every device in
+// DeviceProfiles. Each violation is reported with t.Errorf and all violations
+// are returned.
+//
+// The rules checked are:
+//   - AMAZON.StopIntent and AMAZON.CancelIntent end the session.
+//   - AMAZON.HelpIntent keeps the session open with a prompt and a reprompt.
+//   - A LaunchRequest that keeps the session open has a prompt.
+//   - Every response that keeps the session open has a reprompt.
+//   - A SessionEndedRequest is not answered with speech.
+//   - Every intent in the model is handled without error.
+//
+// Intent requests carry the slots of the intent in the model, without values.
+// The PostResponseHooks of a are not run, so that certification does not
+// record analytics or history.
+func Certify(t testing.TB, a *alexa.Alexa, models map[string]*alexa.InteractionModel) []Violation {
+	t.Helper()
+
+	skill := *a
+	skill.PostResponseHooks = nil
+	a = &skill
+
+	locales := make([]string, 0, len(models))
+	for locale := range models {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+
+	var violations []Violation
+	for _, locale := range locales {
+		model := models[locale]
+		for _, name := range requiredIntents {
+			if model.Intent(name) == nil {
+				violations = append(violations, Violation{Locale: locale, Device: "all", Scenario: name, Problem: "intent is missing from the interaction model"})
+			}
+		}
+		for _, device := range DeviceProfiles {
+			c := &certification{a: a, locale: locale, device: device}
+			c.run(model)
+			violations = append(violations, c.violations...)
+		}
+	}
+
+	for _, v := range violations {
+		t.Errorf("certification: %s", v)
+	}
+	return violations
+}
+
+type certification struct {
+	a          *alexa.Alexa
+	locale     string
+	device     DeviceProfile
+	violations []Violation
+}
+
+func (c *certification) run(model *alexa.InteractionModel) {
+	launch := c.process("LaunchRequest", NewLaunchRequest(c.a.ApplicationID, c.locale))
+	if launch != nil && !launch.ShouldSessionEnd && launch.OutputSpeech == nil {
+		c.report("LaunchRequest", "session is kept open without a prompt")
+	}
+
+	for _, intent := range model.InteractionModel.LanguageModel.Intents {
+		slots := make(map[string]string, len(intent.Slots))
+		for _, slot := range intent.Slots {
+			slots[slot.Name] = ""
+		}
+		response := c.process(intent.Name, NewIntentRequest(c.a.ApplicationID, c.locale, intent.Name, slots))
+		if response == nil {
+			continue
+		}
+		switch intent.Name {
+		case "AMAZON.StopIntent", "AMAZON.CancelIntent":
+			if !response.ShouldSessionEnd {
+				c.report(intent.Name, "session is not ended")
+			}
+		case "AMAZON.HelpIntent":
+			if response.ShouldSessionEnd {
+				c.report(intent.Name, "session is ended")
+			}
+			if response.OutputSpeech == nil {
+				c.report(intent.Name, "response has no prompt")
+			}
+		}
+	}
+
+	ended := c.process("SessionEndedRequest", NewSessionEndedRequest(c.a.ApplicationID, c.locale))
+	if ended != nil && ended.OutputSpeech != nil {
+		c.report("SessionEndedRequest", "response includes speech")
+	}
+}
+
+// process sends the request and checks the rules that apply to every
+// response. It returns nil if the request failed.
+func (c *certification) process(scenario string, requestEnv *alexa.RequestEnvelope) *alexa.Response {
+	c.device.Apply(requestEnv)
+	responseEnv, err := c.a.ProcessRequest(context.Background(), requestEnv)
+	if err != nil {
+		c.report(scenario, "request failed: "+err.Error())
+		return nil
+	}
+	response := responseEnv.Response
+	if !response.ShouldSessionEnd && (response.Reprompt == nil || response.Reprompt.OutputSpeech == nil) {
+		c.report(scenario, "session is kept open without a reprompt")
+	}
+	return response
+}
+
+func (c *certification) report(scenario, problem string) {
+	c.violations = append(c.violations, Violation{Locale: c.locale, Device: c.device.Name, Scenario: scenario, Problem: problem})
+}
@@ -0,0 +1,170 @@
+package alexatest
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	alexa "github.com/ericdaugherty/alexa-skills-kit-golang"
+)
+
+const applicationID = "amzn1.ask.skill.ABC123"
+
+// recorder captures errors reported by Certify instead of failing the test.
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, format)
+}
+
+type certHandler struct {
+	helpEndsSession  bool
+	stopKeepsSession bool
+	noReprompt       bool
+	speakOnEnd       bool
+	failIntent       string
+	slots            map[string]alexa.IntentSlot
+}
+
+func (h *certHandler) OnSessionStarted(context.Context, *alexa.Request, *alexa.Session, *alexa.Context, *alexa.Response) error {
+	return nil
+}
+
+func (h *certHandler) OnLaunch(ctx context.Context, request *alexa.Request, session *alexa.Session, aContext *alexa.Context, response *alexa.Response) error {
+	response.SetOutputText("Welcome, what would you like?")
+	if !h.noReprompt {
+		response.SetRepromptText("What would you like?")
+	}
+	response.ShouldSessionEnd = false
+	return nil
+}
+
+func (h *certHandler) OnIntent(ctx context.Context, request *alexa.Request, session *alexa.Session, aContext *alexa.Context, response *alexa.Response) error {
+	if request.Intent.Name == h.failIntent {
+		return errors.New("unhandled intent")
+	}
+	if request.Intent.Name == "RecipeIntent" {
+		h.slots = request.Intent.Slots
+	}
+	switch request.Intent.Name {
+	case "AMAZON.StopIntent", "AMAZON.CancelIntent":
+		response.SetOutputText("Goodbye")
+		response.ShouldSessionEnd = !h.stopKeepsSession
+	case "AMAZON.HelpIntent":
+		response.SetOutputText("You can ask for a recipe.")
+		response.SetRepromptText("What would you like?")
+		response.ShouldSessionEnd = h.helpEndsSession
+	default:
+		response.SetOutputText("Here is a recipe.")
+	}
+	return nil
+}
+
+func (h *certHandler) OnSessionEnded(ctx context.Context, request *alexa.Request, session *alexa.Session, aContext *alexa.Context, response *alexa.Response) error {
+	if h.speakOnEnd {
+		response.SetOutputText("Bye")
+	}
+	return nil
+}
+
+func certModel(intents ...string) *alexa.InteractionModel {
+	model := &alexa.InteractionModel{}
+	for _, name := range intents {
+		model.InteractionModel.LanguageModel.Intents = append(model.InteractionModel.LanguageModel.Intents, alexa.ModelIntent{Name: name})
+	}
+	return model
+}
+
+func certify(h *certHandler, models map[string]*alexa.InteractionModel) (*recorder, []Violation) {
+	r := &recorder{}
+	a := &alexa.Alexa{ApplicationID: applicationID, RequestHandler: h}
+	return r, Certify(r, a, models)
+}
+
+func TestCertifyPasses(t *testing.T) {
+	model := certModel("AMAZON.StopIntent", "AMAZON.CancelIntent", "AMAZON.HelpIntent", "RecipeIntent")
+	Certify(t, &alexa.Alexa{ApplicationID: applicationID, RequestHandler: &certHandler{}}, map[string]*alexa.InteractionModel{"en-US": model, "de-DE": model})
+}
+
+func TestCertifySendsModelSlotsWithoutHooks(t *testing.T) {
+	model := certModel("AMAZON.StopIntent", "AMAZON.CancelIntent", "AMAZON.HelpIntent", "RecipeIntent")
+	model.InteractionModel.LanguageModel.Intents[3].Slots = []alexa.ModelSlot{{Name: "dish", Type: "DISH"}}
+	h := &certHandler{}
+	hooks := 0
+	a := &alexa.Alexa{ApplicationID: applicationID, RequestHandler: h}
+	a.PostResponseHooks = []alexa.PostResponseHook{func(context.Context, *alexa.RequestEnvelope, *alexa.ResponseEnvelope) error {
+		hooks++
+		return nil
+	}}
+
+	Certify(t, a, map[string]*alexa.InteractionModel{"en-US": model})
+	if slot, ok := h.slots["dish"]; !ok || slot.Name != "dish" || slot.Value != "" {
+		t.Error("Expected the dish slot without a value but slots were", h.slots)
+	}
+	if hooks != 0 {
+		t.Errorf("Expected no post response hooks to run but %d did", hooks)
+	}
+	if len(a.PostResponseHooks) != 1 {
+		t.Error("Expected the skill's hooks to be left unchanged.")
+	}
+}
+
+func TestCertifyReportsEveryViolation(t *testing.T) {
+	model := certModel("AMAZON.StopIntent", "AMAZON.CancelIntent", "AMAZON.HelpIntent", "RecipeIntent")
+	h := &certHandler{helpEndsSession: true, stopKeepsSession: true, noReprompt: true, speakOnEnd: true, failIntent: "RecipeIntent"}
+	r, violations := certify(h, map[string]*alexa.InteractionModel{"en-US": model, "en-GB": model})
+
+	if len(r.errors) != len(violations) {
+		t.Errorf("Expected every violation to be reported but %d of %d were", len(r.errors), len(violations))
+	}
+
+	found := map[string]int{}
+	for _, v := range violations {
+		found[v.Scenario+": "+v.Problem]++
+	}
+	expected := []string{
+		"AMAZON.StopIntent: session is not ended",
+		"AMAZON.CancelIntent: session is not ended",
+		"AMAZON.HelpIntent: session is ended",
+		"LaunchRequest: session is kept open without a reprompt",
+		"SessionEndedRequest: response includes speech",
+		"RecipeIntent: request failed: unhandled intent",
+	}
+	for _, e := range expected {
+		// Each violation is found for 2 locales on every device.
+		if found[e] != 2*len(DeviceProfiles) {
+			t.Errorf("Expected violation %q to be reported %d times but was %d", e, 2*len(DeviceProfiles), found[e])
+		}
+	}
+}
+
+func TestCertifyRequiresBuiltInIntents(t *testing.T) {
+	_, violations := certify(&certHandler{}, map[string]*alexa.InteractionModel{"en-US": certModel("RecipeIntent")})
+
+	missing := 0
+	for _, v := range violations {
+		if strings.Contains(v.Problem, "missing from the interaction model") {
+			missing++
+		}
+	}
+	if missing != 3 {
+		t.Errorf("Expected 3 missing built-in intents but found %d", missing)
+	}
+}
+
+func TestDeviceProfiles(t *testing.T) {
+	requestEnv := NewLaunchRequest(applicationID, "en-US")
+	WithScreen.Apply(requestEnv)
+	if requestEnv.Context.System.Device.SupportedInterfaces.Display == nil {
+		t.Error("Expected WithScreen to add the Display interface.")
+	}
+	if requestEnv.Context.System.APIEndpoint != alexa.RegionNA.APIEndpoint {
+		t.Error("Expected API endpoint to match the locale but was", requestEnv.Context.System.APIEndpoint)
+	}
+}
@@ -0,0 +1,30 @@
+package alexatest
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ericdaugherty/alexa-skills-kit-golang/jsonschema"
+)
+
+// CheckFixtures validates the JSON files matching pattern, such as
+// testdata/requests/*.json, against schema, such as the RequestEnvelope
+// schema of jsonschema.SDK. Unknown fields and wrong types are reported with
+// t.Errorf, naming the file and the field. It fails the test if no file
+// matches, so a moved directory is not silently skipped.
+func CheckFixtures(t testing.TB, schema *jsonschema.Schema, pattern string) {
+	t.Helper()
+
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		t.Fatal("Invalid fixture pattern. " + err.Error())
+	}
+	if len(files) == 0 {
+		t.Errorf("no fixtures match %s", pattern)
+	}
+	for _, f := range files {
+		if err := schema.ValidateFile(f); err != nil {
+			t.Errorf("%v", err)
+		}
+	}
+}
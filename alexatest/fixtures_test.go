@@ -0,0 +1,25 @@
+package alexatest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ericdaugherty/alexa-skills-kit-golang/jsonschema"
+)
+
+func TestCheckFixtures(t *testing.T) {
+	schema := jsonschema.SDK()["RequestEnvelope"]
+	CheckFixtures(t, schema, "testdata/requests/*.json")
+
+	r := &recorder{TB: t}
+	CheckFixtures(r, schema, "testdata/drifted/*.json")
+	if len(r.errors) != 1 {
+		t.Fatal("Expected one drifted fixture but errors were", r.errors)
+	}
+
+	r = &recorder{TB: t}
+	CheckFixtures(r, schema, "testdata/missing/*.json")
+	if len(r.errors) != 1 || !strings.HasPrefix(r.errors[0], "no fixtures") {
+		t.Error("Expected a missing directory to fail but errors were", r.errors)
+	}
+}
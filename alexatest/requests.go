@@ -0,0 +1,99 @@
+// Package alexatest provides helpers for testing skills built with the alexa
+// package: request builders and a certification pre-check suite.
+package alexatest
+
+import (
+	"time"
+
+	alexa "github.com/ericdaugherty/alexa-skills-kit-golang"
+)
+
+const (
+	// UserID is the user ID set on requests built by this package.
+	UserID = "amzn1.ask.account.alexatest"
+	// DeviceID is the device ID set on requests built by this package.
+	DeviceID = "amzn1.ask.device.alexatest"
+	// SessionID is the session ID set on requests built by this package.
+	SessionID = "amzn1.echo-api.session.alexatest"
+)
+
+// NewRequest builds a request of requestType for the skill with appID, as
+// sent by a device with locale at the current time. The session is new.
+func NewRequest(appID, locale, requestType string) *alexa.RequestEnvelope {
+	requestEnv := &alexa.RequestEnvelope{
+		Version: "1.0",
+		Session: &alexa.Session{},
+		Request: &alexa.Request{},
+		Context: &alexa.Context{},
+	}
+	requestEnv.Session.New = true
+	requestEnv.Session.SessionID = SessionID
+	requestEnv.Session.Application.ApplicationID = appID
+	requestEnv.Session.User.UserID = UserID
+	requestEnv.Session.Attributes.String = make(map[string]interface{})
+
+	requestEnv.Context.System.Application.ApplicationID = appID
+	requestEnv.Context.System.User.UserID = UserID
+	requestEnv.Context.System.Device.DeviceID = DeviceID
+	requestEnv.Context.System.APIEndpoint = alexa.RegionForLocale(locale).APIEndpoint
+
+	requestEnv.Request.Type = requestType
+	requestEnv.Request.RequestID = "amzn1.echo-api.request.alexatest"
+	requestEnv.Request.Locale = locale
+	requestEnv.Request.Timestamp = time.Now().UTC().Format(time.RFC3339)
+	return requestEnv
+}
+
+// NewLaunchRequest builds a LaunchRequest starting a new session.
+func NewLaunchRequest(appID, locale string) *alexa.RequestEnvelope {
+	return NewRequest(appID, locale, "LaunchRequest")
+}
+
+// NewIntentRequest builds an IntentRequest for intent within an existing
+// session. Slots are set from the name/value pairs in slots.
+func NewIntentRequest(appID, locale, intent string, slots map[string]string) *alexa.RequestEnvelope {
+	requestEnv := NewRequest(appID, locale, "IntentRequest")
+	requestEnv.Session.New = false
+	requestEnv.Request.Intent = alexa.Intent{
+		Name:               intent,
+		ConfirmationStatus: "NONE",
+		Slots:              make(map[string]alexa.IntentSlot),
+	}
+	for name, value := range slots {
+		requestEnv.Request.Intent.Slots[name] = alexa.IntentSlot{Name: name, Value: value, ConfirmationStatus: "NONE"}
+	}
+	return requestEnv
+}
+
+// NewSessionEndedRequest builds a SessionEndedRequest for an existing session.
+func NewSessionEndedRequest(appID, locale string) *alexa.RequestEnvelope {
+	requestEnv := NewRequest(appID, locale, "SessionEndedRequest")
+	requestEnv.Session.New = false
+	return requestEnv
+}
+
+// DeviceProfile describes the interfaces supported by a kind of device.
+type DeviceProfile struct {
+	Name  string
+	Apply func(requestEnv *alexa.RequestEnvelope)
+}
+
+// VoiceOnly is a device without a screen, such as an Echo Dot.
+var VoiceOnly = DeviceProfile{
+	Name:  "voice only",
+	Apply: func(requestEnv *alexa.RequestEnvelope) {},
+}
+
+// WithScreen is a device with a screen that supports APL, such as an Echo Show.
+var WithScreen = DeviceProfile{
+	Name: "with screen",
+	Apply: func(requestEnv *alexa.RequestEnvelope) {
+		interfaces := &requestEnv.Context.System.Device.SupportedInterfaces
+		interfaces.Display = &alexa.DisplayInterface{TemplateVersion: "1.0", MarkupVersion: "1.0"}
+		interfaces.AlexaPresentationAPL = &alexa.APLInterface{}
+		interfaces.AlexaPresentationAPL.Runtime.MaxVersion = "1.6"
+	},
+}
+
+// DeviceProfiles lists the device profiles used by Certify.
+var DeviceProfiles = []DeviceProfile{VoiceOnly, WithScreen}
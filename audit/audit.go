@@ -0,0 +1,111 @@
+// Package audit records an append-only trail of sensitive operations, such as
+// purchases, account changes and data deletions, for compliance. Users are
+// identified by a keyed pseudonym rather than their Alexa IDs. Records are
+// written to a Sink; FileSink writes a JSONL file chained with keyed hashes
+// whose integrity can be checked with Verify or the alexa-audit-verify command.
+package audit
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	alexa "github.com/ericdaugherty/alexa-skills-kit-golang"
+)
+
+// Outcomes of an audited operation.
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+	OutcomeDenied  = "denied"
+)
+
+// ErrKeyMissing reports that a Logger or FileSink has no key.
+var ErrKeyMissing = errors.New("audit: key is required")
+
+// ErrRequestMissing reports that a record was made without the request or its
+// context.
+var ErrRequestMissing = errors.New("audit: request and context are required")
+
+// Record is a single entry in the audit trail. Seq, PrevHash and Hash are set
+// by the Sink.
+type Record struct {
+	Seq       int64             `json:"seq"`
+	Time      time.Time         `json:"time"`
+	Who       string            `json:"who"`
+	Person    string            `json:"person,omitempty"`
+	Action    string            `json:"action"`
+	RequestID string            `json:"requestId"`
+	Outcome   string            `json:"outcome"`
+	Details   map[string]string `json:"details,omitempty"`
+	PrevHash  string            `json:"prevHash"`
+	Hash      string            `json:"hash"`
+}
+
+// Sink stores audit records. Implementations must be append-only and safe for
+// concurrent use.
+type Sink interface {
+	Append(ctx context.Context, record *Record) error
+}
+
+// Logger creates audit records for handlers. Key is the secret used to
+// pseudonymize user and person IDs, and must stay the same for records of a
+// user to be correlated. Values of SensitiveSlots are masked in details.
+type Logger struct {
+	Sink           Sink
+	Key            []byte
+	SensitiveSlots *alexa.SensitiveSlots
+	Now            func() time.Time
+}
+
+// NewLogger creates a Logger writing to sink.
+func NewLogger(sink Sink, key []byte) *Logger {
+	return &Logger{Sink: sink, Key: key}
+}
+
+// Record appends a record of action and its outcome, performed by the user
+// and recognized person of the request. Details must not contain personal data.
+func (l *Logger) Record(ctx context.Context, request *alexa.Request, aContext *alexa.Context, action, outcome string, details map[string]string) error {
+	if len(l.Key) == 0 {
+		return ErrKeyMissing
+	}
+	if request == nil || aContext == nil {
+		return ErrRequestMissing
+	}
+	now := time.Now
+	if l.Now != nil {
+		now = l.Now
+	}
+
+	record := &Record{
+		Time:      now().UTC(),
+		Who:       Pseudonymize(l.Key, aContext.System.User.UserID),
+		Action:    action,
+		RequestID: request.RequestID,
+		Outcome:   outcome,
+	}
+	if len(details) > 0 {
+		record.Details = make(map[string]string, len(details))
+		for k, v := range details {
+			record.Details[k] = l.SensitiveSlots.MaskText(&request.Intent, v)
+		}
+	}
+	if aContext.System.Person != nil && aContext.System.Person.PersonID != "" {
+		record.Person = Pseudonymize(l.Key, aContext.System.Person.PersonID)
+	}
+	return l.Sink.Append(ctx, record)
+}
+
+// Pseudonymize returns a stable pseudonym for id that cannot be reversed
+// without key.
+func Pseudonymize(key []byte, id string) string {
+	if id == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(id))
+	return hex.EncodeToString(mac.Sum(nil))
+}
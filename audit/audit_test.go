@@ -0,0 +1,148 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	alexa "github.com/ericdaugherty/alexa-skills-kit-golang"
+)
+
+const userID = "amzn1.ask.account.SECRETUSER"
+
+var key = []byte("key")
+
+func newRequest() (*alexa.Request, *alexa.Context) {
+	request := &alexa.Request{RequestID: "amzn1.echo-api.request.xyz789"}
+	aContext := &alexa.Context{}
+	aContext.System.User.UserID = userID
+	aContext.System.Person = &alexa.Person{PersonID: "amzn1.ask.person.SECRETPERSON"}
+	return request, aContext
+}
+
+func TestFileSinkChain(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	sink, err := OpenFile(path, key)
+	if err != nil {
+		t.Fatal("Error opening audit file. " + err.Error())
+	}
+	logger := NewLogger(sink, key)
+	request, aContext := newRequest()
+	ctx := context.Background()
+
+	if err := logger.Record(ctx, request, aContext, "purchase", OutcomeSuccess, map[string]string{"product": "premium"}); err != nil {
+		t.Fatal("Error recording. " + err.Error())
+	}
+	if err := logger.Record(ctx, request, aContext, "delete-data", OutcomeDenied, nil); err != nil {
+		t.Fatal("Error recording. " + err.Error())
+	}
+	sink.Close()
+
+	// Reopening continues the chain.
+	sink, err = OpenFile(path, key)
+	if err != nil {
+		t.Fatal("Error reopening audit file. " + err.Error())
+	}
+	logger.Sink = sink
+	if err := logger.Record(ctx, request, aContext, "account-change", OutcomeFailure, nil); err != nil {
+		t.Fatal("Error recording. " + err.Error())
+	}
+	sink.Close()
+
+	b, _ := os.ReadFile(path)
+	if strings.Contains(string(b), "SECRETUSER") || strings.Contains(string(b), "SECRETPERSON") {
+		t.Error("Expected user and person IDs to be pseudonymized but file was", string(b))
+	}
+
+	n, err := Verify(strings.NewReader(string(b)), key)
+	if err != nil {
+		t.Fatal("Expected audit file to verify but got error", err)
+	}
+	if n != 3 {
+		t.Errorf("Expected 3 records but there were %d", n)
+	}
+}
+
+func TestVerifyDetectsTampering(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	sink, _ := OpenFile(path, key)
+	logger := NewLogger(sink, key)
+	request, aContext := newRequest()
+	for _, action := range []string{"purchase", "refund", "delete-data"} {
+		logger.Record(context.Background(), request, aContext, action, OutcomeSuccess, nil)
+	}
+	sink.Close()
+	b, _ := os.ReadFile(path)
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+
+	modified := strings.Replace(string(b), `"outcome":"success"`, `"outcome":"failure"`, 1)
+	if _, err := Verify(strings.NewReader(modified), key); !errors.Is(err, ErrChainBroken) {
+		t.Error("Expected a modified record to break the chain but got", err)
+	}
+
+	removed := lines[0] + "\n" + lines[2] + "\n"
+	if _, err := Verify(strings.NewReader(removed), key); !errors.Is(err, ErrChainBroken) {
+		t.Error("Expected a removed record to break the chain but got", err)
+	}
+
+	if _, err := Verify(strings.NewReader(string(b)), []byte("other")); !errors.Is(err, ErrChainBroken) {
+		t.Error("Expected verifying with another key to break the chain but got", err)
+	}
+
+	os.WriteFile(path, []byte(modified), 0600)
+	if _, err := OpenFile(path, key); !errors.Is(err, ErrChainBroken) {
+		t.Error("Expected opening a modified file to fail but got", err)
+	}
+}
+
+func TestPseudonymize(t *testing.T) {
+	a := Pseudonymize([]byte("key"), userID)
+	if a != Pseudonymize([]byte("key"), userID) {
+		t.Error("Expected pseudonym to be stable.")
+	}
+	if a == Pseudonymize([]byte("other"), userID) {
+		t.Error("Expected pseudonym to depend on the key.")
+	}
+	if Pseudonymize([]byte("key"), "") != "" {
+		t.Error("Expected an empty ID to have an empty pseudonym.")
+	}
+}
+
+func TestLoggerRequiresKey(t *testing.T) {
+	request, aContext := newRequest()
+	logger := NewLogger(nil, nil)
+	if err := logger.Record(context.Background(), request, aContext, "purchase", OutcomeSuccess, nil); err != ErrKeyMissing {
+		t.Error("Expected ErrKeyMissing but got", err)
+	}
+	if _, err := OpenFile(filepath.Join(t.TempDir(), "audit.jsonl"), nil); err != ErrKeyMissing {
+		t.Error("Expected ErrKeyMissing opening a file without a key but got", err)
+	}
+}
+
+func TestLoggerRequiresContext(t *testing.T) {
+	request, _ := newRequest()
+	logger := NewLogger(nil, key)
+	if err := logger.Record(context.Background(), request, nil, "purchase", OutcomeSuccess, nil); err != ErrRequestMissing {
+		t.Error("Expected ErrRequestMissing but got", err)
+	}
+}
+
+func TestLoggerMasksSensitiveSlots(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	sink, _ := OpenFile(path, key)
+	defer sink.Close()
+	logger := NewLogger(sink, key)
+	logger.SensitiveSlots = alexa.NewSensitiveSlots([]string{"account"}, nil)
+
+	request, aContext := newRequest()
+	request.Intent = alexa.Intent{Name: "TransferIntent", Slots: map[string]alexa.IntentSlot{"account": {Name: "account", Value: "12345678"}}}
+	logger.Record(context.Background(), request, aContext, "transfer", OutcomeSuccess, map[string]string{"to": "account 12345678"})
+
+	b, _ := os.ReadFile(path)
+	if strings.Contains(string(b), "12345678") {
+		t.Error("Expected sensitive slot value to be masked in details but file was", string(b))
+	}
+}
@@ -0,0 +1,152 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// ErrChainBroken reports that an audit file was modified after it was written.
+var ErrChainBroken = errors.New("audit: hash chain is broken")
+
+// FileSink appends records to a JSONL file. Each record contains the hash of
+// the previous record, so that any change to the file breaks the chain. The
+// hashes are keyed, so the chain cannot be rewritten without the key.
+type FileSink struct {
+	mu       sync.Mutex
+	f        *os.File
+	key      []byte
+	seq      int64
+	lastHash string
+}
+
+// OpenFile opens or creates the audit file at path, verifying the existing
+// records with key before new ones are appended.
+func OpenFile(path string, key []byte) (*FileSink, error) {
+	if len(key) == 0 {
+		return nil, ErrKeyMissing
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	last, err := verify(f, key)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	s := &FileSink{f: f, key: key}
+	if last != nil {
+		s.seq = last.Seq
+		s.lastHash = last.Hash
+	}
+	return s, nil
+}
+
+// Append chains record to the previous record and writes it to the file.
+func (s *FileSink) Append(ctx context.Context, record *Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r := *record
+	r.Seq = s.seq + 1
+	r.PrevHash = s.lastHash
+	hash, err := hashRecord(s.key, &r)
+	if err != nil {
+		return err
+	}
+	r.Hash = hash
+
+	b, err := json.Marshal(&r)
+	if err != nil {
+		return err
+	}
+	if _, err := s.f.Write(append(b, '\n')); err != nil {
+		return err
+	}
+	if err := s.f.Sync(); err != nil {
+		return err
+	}
+
+	s.seq = r.Seq
+	s.lastHash = r.Hash
+	*record = r
+	return nil
+}
+
+// Close closes the file.
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.f.Close()
+}
+
+// Verify checks the hash chain of an audit file written with key and returns
+// the number of records. The returned error wraps ErrChainBroken if the file
+// was modified.
+func Verify(r io.Reader, key []byte) (int64, error) {
+	if len(key) == 0 {
+		return 0, ErrKeyMissing
+	}
+	last, err := verify(r, key)
+	if last == nil {
+		return 0, err
+	}
+	return last.Seq, err
+}
+
+func verify(r io.Reader, key []byte) (*Record, error) {
+	var last *Record
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		record := &Record{}
+		if err := json.Unmarshal(scanner.Bytes(), record); err != nil {
+			return last, fmt.Errorf("%w: line %d is not a record: %v", ErrChainBroken, line, err)
+		}
+
+		expectedSeq, expectedPrev := int64(1), ""
+		if last != nil {
+			expectedSeq, expectedPrev = last.Seq+1, last.Hash
+		}
+		if record.Seq != expectedSeq {
+			return last, fmt.Errorf("%w: line %d has sequence %d, expected %d", ErrChainBroken, line, record.Seq, expectedSeq)
+		}
+		if record.PrevHash != expectedPrev {
+			return last, fmt.Errorf("%w: line %d does not follow the previous record", ErrChainBroken, line)
+		}
+		hash, err := hashRecord(key, record)
+		if err != nil {
+			return last, err
+		}
+		if hash != record.Hash {
+			return last, fmt.Errorf("%w: line %d was modified", ErrChainBroken, line)
+		}
+		last = record
+	}
+	return last, scanner.Err()
+}
+
+// hashRecord computes the HMAC of the record with its Hash field cleared.
+func hashRecord(key []byte, record *Record) (string, error) {
+	r := *record
+	r.Hash = ""
+	b, err := json.Marshal(&r)
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(b)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
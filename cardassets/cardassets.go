@@ -0,0 +1,138 @@
+// Package cardassets prepares the images of Standard cards. Process resizes a
+// source image to the recommended small and large sizes, a Manifest maps
+// logical names to the URLs of the variants in each environment, and
+// Validator checks at response time that card images are HTTPS and
+// registered. It backs the alexa-card-assets command.
+package cardassets
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+)
+
+// Recommended sizes of Standard card images.
+var (
+	SmallSize = image.Pt(720, 480)
+	LargeSize = image.Pt(1200, 800)
+)
+
+// ErrSourceTooSmall reports that a source image is smaller than LargeSize and
+// would have to be enlarged.
+var ErrSourceTooSmall = errors.New("cardassets: source image is smaller than the large size")
+
+// Variants are the file names of the resized images.
+type Variants struct {
+	Small string
+	Large string
+}
+
+// Process resizes the image at src to SmallSize and LargeSize and writes the
+// variants to dir as name-small and name-large. JPEG sources are written as
+// JPEG, anything else as PNG.
+func Process(src, dir, name string) (Variants, error) {
+	f, err := os.Open(src)
+	if err != nil {
+		return Variants{}, err
+	}
+	img, format, err := image.Decode(f)
+	f.Close()
+	if err != nil {
+		return Variants{}, fmt.Errorf("cardassets: decoding %s: %w", src, err)
+	}
+	if b := img.Bounds(); b.Dx() < LargeSize.X || b.Dy() < LargeSize.Y {
+		return Variants{}, fmt.Errorf("%w: %s is %dx%d", ErrSourceTooSmall, src, b.Dx(), b.Dy())
+	}
+
+	ext := ".png"
+	if format == "jpeg" {
+		ext = ".jpg"
+	}
+	v := Variants{Small: name + "-small" + ext, Large: name + "-large" + ext}
+	if err := writeImage(filepath.Join(dir, v.Small), Resize(img, SmallSize.X, SmallSize.Y)); err != nil {
+		return Variants{}, err
+	}
+	if err := writeImage(filepath.Join(dir, v.Large), Resize(img, LargeSize.X, LargeSize.Y)); err != nil {
+		return Variants{}, err
+	}
+	return v, nil
+}
+
+func writeImage(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if filepath.Ext(path) == ".jpg" {
+		err = jpeg.Encode(f, img, &jpeg.Options{Quality: 90})
+	} else {
+		err = png.Encode(f, img)
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// Resize scales src to cover width by height, cropping the excess equally
+// from both sides, and averages the source pixels under each destination
+// pixel.
+func Resize(src image.Image, width, height int) *image.RGBA {
+	b := src.Bounds()
+	rgba, ok := src.(*image.RGBA)
+	if !ok {
+		rgba = image.NewRGBA(b)
+		draw.Draw(rgba, b, src, b.Min, draw.Src)
+	}
+
+	scale := math.Max(float64(width)/float64(b.Dx()), float64(height)/float64(b.Dy()))
+	cropW, cropH := float64(width)/scale, float64(height)/scale
+	x0 := float64(b.Min.X) + (float64(b.Dx())-cropW)/2
+	y0 := float64(b.Min.Y) + (float64(b.Dy())-cropH)/2
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		sy0, sy1 := span(y0, cropH/float64(height), y, b.Max.Y)
+		for x := 0; x < width; x++ {
+			sx0, sx1 := span(x0, cropW/float64(width), x, b.Max.X)
+			var r, g, bl, a, n uint32
+			for sy := sy0; sy < sy1; sy++ {
+				i := rgba.PixOffset(sx0, sy)
+				for sx := sx0; sx < sx1; sx++ {
+					r += uint32(rgba.Pix[i])
+					g += uint32(rgba.Pix[i+1])
+					bl += uint32(rgba.Pix[i+2])
+					a += uint32(rgba.Pix[i+3])
+					n++
+					i += 4
+				}
+			}
+			i := dst.PixOffset(x, y)
+			dst.Pix[i] = uint8(r / n)
+			dst.Pix[i+1] = uint8(g / n)
+			dst.Pix[i+2] = uint8(bl / n)
+			dst.Pix[i+3] = uint8(a / n)
+		}
+	}
+	return dst
+}
+
+// span returns the source pixels covered by destination pixel i, always at
+// least one.
+func span(origin, step float64, i, max int) (int, int) {
+	start := int(origin + float64(i)*step)
+	end := int(math.Ceil(origin + float64(i+1)*step))
+	if end > max {
+		end = max
+	}
+	if end <= start {
+		end = start + 1
+	}
+	return start, end
+}
@@ -0,0 +1,110 @@
+package cardassets
+
+import (
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	alexa "github.com/ericdaugherty/alexa-skills-kit-golang"
+)
+
+func writeSource(t *testing.T, path string, width, height int) {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			// Red on the left half, blue on the right.
+			if x < width/2 {
+				img.Set(x, y, color.RGBA{255, 0, 0, 255})
+			} else {
+				img.Set(x, y, color.RGBA{0, 0, 255, 255})
+			}
+		}
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	png.Encode(f, img)
+}
+
+func TestProcess(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "logo.png")
+	writeSource(t, src, 1600, 1200)
+
+	v, err := Process(src, dir, "logo")
+	if err != nil {
+		t.Fatal("Error processing image. " + err.Error())
+	}
+	for file, size := range map[string]image.Point{v.Small: SmallSize, v.Large: LargeSize} {
+		f, err := os.Open(filepath.Join(dir, file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		img, err := png.Decode(f)
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if img.Bounds().Size() != size {
+			t.Errorf("Expected %s to be %v but was %v", file, size, img.Bounds().Size())
+		}
+		if r, _, b, _ := img.At(10, 10).RGBA(); r>>8 != 255 || b != 0 {
+			t.Error("Expected left of", file, "to be red but was", img.At(10, 10))
+		}
+	}
+}
+
+func TestProcessSourceTooSmall(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "small.png")
+	writeSource(t, src, 800, 600)
+	if _, err := Process(src, dir, "small"); !errors.Is(err, ErrSourceTooSmall) {
+		t.Error("Expected ErrSourceTooSmall but got", err)
+	}
+}
+
+func TestValidator(t *testing.T) {
+	m := Manifest{}
+	m.Add("prod", "https://cdn.example.com/cards/", "logo", Variants{Small: "logo-small.png", Large: "logo-large.png"})
+
+	path := filepath.Join(t.TempDir(), "cards.json")
+	if err := m.WriteFile(path); err != nil {
+		t.Fatal(err)
+	}
+	m, err := ReadManifest(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := &Validator{Manifest: m, Environment: "prod"}
+
+	responseEnv := &alexa.ResponseEnvelope{Response: &alexa.Response{}}
+	if err := v.SetStandardCard(responseEnv.Response, "Title", "Text", "logo"); err != nil {
+		t.Fatal(err)
+	}
+	if responseEnv.Response.Card.Image.LargeImageURL != "https://cdn.example.com/cards/logo-large.png" {
+		t.Error("Expected manifest URL but was", responseEnv.Response.Card.Image.LargeImageURL)
+	}
+	if err := v.ValidateResponse(context.Background(), nil, responseEnv); err != nil {
+		t.Error("Expected registered card to be valid but got", err)
+	}
+
+	if err := v.SetStandardCard(responseEnv.Response, "Title", "Text", "missing"); !errors.Is(err, ErrUnknownAsset) {
+		t.Error("Expected ErrUnknownAsset but got", err)
+	}
+
+	responseEnv.Response.SetStandardCard("Title", "Text", "http://cdn.example.com/cards/logo-small.png", "")
+	if err := v.ValidateResponse(context.Background(), nil, responseEnv); !errors.Is(err, ErrInsecureURL) {
+		t.Error("Expected ErrInsecureURL but got", err)
+	}
+	responseEnv.Response.SetStandardCard("Title", "Text", "https://cdn.example.com/cards/other.png", "")
+	if err := v.ValidateResponse(context.Background(), nil, responseEnv); !errors.Is(err, ErrUnregisteredURL) {
+		t.Error("Expected ErrUnregisteredURL but got", err)
+	}
+}
@@ -0,0 +1,106 @@
+package cardassets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	alexa "github.com/ericdaugherty/alexa-skills-kit-golang"
+)
+
+// Errors reported by Validator.
+var (
+	ErrInsecureURL     = errors.New("cardassets: card image URL is not HTTPS")
+	ErrUnregisteredURL = errors.New("cardassets: card image URL is not in the manifest")
+	ErrUnknownAsset    = errors.New("cardassets: no asset with that name")
+)
+
+// Manifest maps an environment, such as dev or prod, to the images of each
+// logical name.
+type Manifest map[string]map[string]alexa.Image
+
+// ReadManifest reads a Manifest from a JSON file.
+func ReadManifest(path string) (Manifest, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	m := Manifest{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// WriteFile writes the Manifest as JSON to path.
+func (m Manifest) WriteFile(path string) error {
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(b, '\n'), 0644)
+}
+
+// Add registers the variants of name in env, served from baseURL.
+func (m Manifest) Add(env, baseURL, name string, v Variants) {
+	if m[env] == nil {
+		m[env] = make(map[string]alexa.Image)
+	}
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	m[env][name] = alexa.Image{SmallImageURL: baseURL + "/" + v.Small, LargeImageURL: baseURL + "/" + v.Large}
+}
+
+// Image returns the images of name in env.
+func (m Manifest) Image(env, name string) (alexa.Image, bool) {
+	img, ok := m[env][name]
+	return img, ok
+}
+
+// Validator is an alexa.ResponseValidator that checks the image URLs of
+// Standard cards are HTTPS and registered in Environment of Manifest.
+type Validator struct {
+	Manifest    Manifest
+	Environment string
+}
+
+// SetStandardCard sets a Standard card on r with the images of name.
+func (v *Validator) SetStandardCard(r *alexa.Response, title, text, name string) error {
+	img, ok := v.Manifest.Image(v.Environment, name)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownAsset, name)
+	}
+	r.SetStandardCard(title, text, img.SmallImageURL, img.LargeImageURL)
+	return nil
+}
+
+// ValidateResponse checks the card of responseEnv.
+func (v *Validator) ValidateResponse(ctx context.Context, requestEnv *alexa.RequestEnvelope, responseEnv *alexa.ResponseEnvelope) error {
+	r := responseEnv.Response
+	if r == nil || r.Card == nil || r.Card.Type != "Standard" || r.Card.Image == nil {
+		return nil
+	}
+	for _, u := range []string{r.Card.Image.SmallImageURL, r.Card.Image.LargeImageURL} {
+		if u == "" {
+			continue
+		}
+		if !strings.HasPrefix(u, "https://") {
+			return fmt.Errorf("%w: %s", ErrInsecureURL, u)
+		}
+		if !v.registered(u) {
+			return fmt.Errorf("%w: %s", ErrUnregisteredURL, u)
+		}
+	}
+	return nil
+}
+
+func (v *Validator) registered(u string) bool {
+	for _, img := range v.Manifest[v.Environment] {
+		if img.SmallImageURL == u || img.LargeImageURL == u {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+// Command alexa-audit-verify checks the hash chain of audit files written by
+// audit.FileSink and exits with a non-zero status if any was modified. The key
+// the files were written with is read from the AUDIT_KEY environment variable.
+//
+//	AUDIT_KEY=... alexa-audit-verify audit.jsonl
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ericdaugherty/alexa-skills-kit-golang/audit"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, "usage: alexa-audit-verify audit.jsonl...")
+		os.Exit(2)
+	}
+	key := []byte(os.Getenv("AUDIT_KEY"))
+	if len(key) == 0 {
+		fmt.Fprintln(os.Stderr, "AUDIT_KEY must be set to the key the audit files were written with")
+		os.Exit(2)
+	}
+
+	failed := false
+	for _, path := range os.Args[1:] {
+		f, err := os.Open(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			failed = true
+			continue
+		}
+		n, err := audit.Verify(f, key)
+		f.Close()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+			failed = true
+			continue
+		}
+		fmt.Printf("%s: %d records verified\n", path, n)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,73 @@
+// Command alexa-card-assets resizes a source image to the recommended small
+// and large Standard card sizes and registers them in a manifest under each
+// environment.
+//
+//	alexa-card-assets -name logo -out assets -manifest cards.json \
+//		-env dev=https://dev.example.com/cards -env prod=https://cdn.example.com/cards logo.png
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ericdaugherty/alexa-skills-kit-golang/cardassets"
+)
+
+// envFlags collects repeated -env name=baseURL flags.
+type envFlags map[string]string
+
+func (e envFlags) String() string {
+	return fmt.Sprint(map[string]string(e))
+}
+
+func (e envFlags) Set(s string) error {
+	name, baseURL, ok := strings.Cut(s, "=")
+	if !ok || name == "" || baseURL == "" {
+		return errors.New("expected name=baseURL")
+	}
+	e[name] = baseURL
+	return nil
+}
+
+func main() {
+	envs := envFlags{}
+	name := flag.String("name", "", "logical name of the image")
+	out := flag.String("out", ".", "directory to write the variants to")
+	manifestPath := flag.String("manifest", "cards.json", "manifest to update")
+	flag.Var(envs, "env", "environment and base URL of the variants, as name=baseURL; repeatable")
+	flag.Parse()
+
+	if flag.NArg() != 1 || *name == "" || len(envs) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: alexa-card-assets -name name -env env=baseURL [-out dir] [-manifest cards.json] image")
+		os.Exit(2)
+	}
+
+	manifest, err := cardassets.ReadManifest(*manifestPath)
+	if errors.Is(err, os.ErrNotExist) {
+		manifest, err = cardassets.Manifest{}, nil
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	v, err := cardassets.Process(flag.Arg(0), *out, *name)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	for env, baseURL := range envs {
+		if !strings.HasPrefix(baseURL, "https://") {
+			fmt.Fprintf(os.Stderr, "warning: %s base URL %s is not HTTPS\n", env, baseURL)
+		}
+		manifest.Add(env, baseURL, *name, v)
+	}
+	if err := manifest.WriteFile(*manifestPath); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Printf("%s: wrote %s and %s\n", *name, v.Small, v.Large)
+}
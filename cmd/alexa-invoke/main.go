@@ -0,0 +1,14 @@
+// Command alexa-invoke posts an Alexa request JSON fixture to a skill served
+// over HTTP and prints the response, with SSML rendered as readable text.
+// The request timestamp is refreshed so that timestamp validation passes.
+//
+//	alexa-invoke -url http://localhost:8080/ -app-id amzn1.ask.skill.<SKILL_ID> request.json
+//
+// To invoke a skill in-process instead, see the invoke package.
+package main
+
+import "github.com/ericdaugherty/alexa-skills-kit-golang/invoke"
+
+func main() {
+	invoke.Main()
+}
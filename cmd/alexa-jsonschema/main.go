@@ -0,0 +1,76 @@
+// Command alexa-jsonschema writes the JSON Schemas of the SDK types, or
+// checks JSON fixtures against one of them.
+//
+//	alexa-jsonschema -out schemas
+//	alexa-jsonschema -check RequestEnvelope testdata/requests/*.json
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/ericdaugherty/alexa-skills-kit-golang/jsonschema"
+)
+
+func main() {
+	out := flag.String("out", "", "directory to write the schemas to, as name.schema.json")
+	check := flag.String("check", "", "name of the schema to check the files against")
+	flag.Parse()
+
+	schemas := jsonschema.SDK()
+	switch {
+	case *out != "" && flag.NArg() == 0:
+		if err := write(schemas, *out); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	case *check != "" && flag.NArg() > 0:
+		schema, ok := schemas[*check]
+		if !ok {
+			fmt.Fprintln(os.Stderr, "unknown schema", *check+"; one of", names(schemas))
+			os.Exit(2)
+		}
+		failed := false
+		for _, f := range flag.Args() {
+			if err := schema.ValidateFile(f); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				failed = true
+			}
+		}
+		if failed {
+			os.Exit(1)
+		}
+	default:
+		fmt.Fprintln(os.Stderr, "usage: alexa-jsonschema -out dir | -check name file...")
+		os.Exit(2)
+	}
+}
+
+func write(schemas map[string]*jsonschema.Schema, dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for name, s := range schemas {
+		b, err := json.MarshalIndent(s, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, name+".schema.json"), append(b, '\n'), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func names(schemas map[string]*jsonschema.Schema) []string {
+	var n []string
+	for name := range schemas {
+		n = append(n, name)
+	}
+	sort.Strings(n)
+	return n
+}
@@ -0,0 +1,173 @@
+package alexa
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ErrComplianceViolation is matched by errors.Is for a *ComplianceError.
+var ErrComplianceViolation = errors.New("response violates compliance rules")
+
+// ResponseValidator checks a response before it is returned to Alexa. An
+// error fails the request.
+type ResponseValidator interface {
+	ValidateResponse(ctx context.Context, requestEnv *RequestEnvelope, responseEnv *ResponseEnvelope) error
+}
+
+// Rules checked by KidCompliance.
+const (
+	RuleLinkAccountCard     = "link-account-card"
+	RulePermissionsCard     = "permissions-card"
+	RulePurchaseDirective   = "purchase-directive"
+	RulePersonalDataRequest = "personal-data-request"
+)
+
+// ComplianceViolation is a single broken rule.
+type ComplianceViolation struct {
+	Rule   string
+	Detail string
+}
+
+func (v ComplianceViolation) String() string {
+	return v.Rule + ": " + v.Detail
+}
+
+// ComplianceError lists every violation found in a response.
+type ComplianceError struct {
+	Violations []ComplianceViolation
+}
+
+func (e *ComplianceError) Error() string {
+	s := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		s[i] = v.String()
+	}
+	return ErrComplianceViolation.Error() + ": " + strings.Join(s, "; ")
+}
+
+// Unwrap returns ErrComplianceViolation.
+func (e *ComplianceError) Unwrap() error {
+	return ErrComplianceViolation
+}
+
+// DefaultPersonalDataPatterns match speech asking for a name, address, phone
+// number or email address.
+var DefaultPersonalDataPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)\bwhat(?:'s| is) your (?:full |first |last |real )?name\b`),
+	regexp.MustCompile(`(?i)\b(?:tell|give|say) (?:me|us) your (?:full |first |last |real )?name\b`),
+	regexp.MustCompile(`(?i)\b(?:what(?:'s| is)|tell me|give me|say) your (?:home |street |email |e-mail |mailing )?address\b`),
+	regexp.MustCompile(`(?i)\bwhere do you live\b`),
+	regexp.MustCompile(`(?i)\b(?:what(?:'s| is)|tell me|give me|say) your (?:phone|telephone|mobile|cell) number\b`),
+}
+
+// KidCompliance is a ResponseValidator for skills directed to children. It
+// rejects account linking and permission consent cards, in-skill purchasing
+// and shopping Connections directives unless AllowPurchases is set, and
+// speech matching PersonalDataPatterns, or DefaultPersonalDataPatterns if it
+// is nil.
+//
+// Violations are returned as a *ComplianceError.
+type KidCompliance struct {
+	// AllowPurchases permits Buy, Upsell and Cancel requests and shopping
+	// tasks, for skills whose purchases are protected by parental controls.
+	AllowPurchases       bool
+	PersonalDataPatterns []*regexp.Regexp
+}
+
+// ValidateResponse checks responseEnv against the rules.
+func (k *KidCompliance) ValidateResponse(ctx context.Context, requestEnv *RequestEnvelope, responseEnv *ResponseEnvelope) error {
+	r := responseEnv.Response
+	if r == nil {
+		return nil
+	}
+	var violations []ComplianceViolation
+
+	if r.Card != nil {
+		switch r.Card.Type {
+		case "LinkAccount":
+			violations = append(violations, ComplianceViolation{RuleLinkAccountCard, "LinkAccount card"})
+		case "AskForPermissionsConsent":
+			violations = append(violations, ComplianceViolation{RulePermissionsCard, "AskForPermissionsConsent card for " + strings.Join(r.Card.Permissions, ", ")})
+		}
+	}
+
+	if !k.AllowPurchases {
+		for _, d := range r.Directives {
+			if name, ok := purchaseDirective(d); ok {
+				violations = append(violations, ComplianceViolation{RulePurchaseDirective, name})
+			}
+		}
+	}
+
+	patterns := k.PersonalDataPatterns
+	if patterns == nil {
+		patterns = DefaultPersonalDataPatterns
+	}
+	speech := map[string]*OutputSpeech{"speech": r.OutputSpeech}
+	if r.Reprompt != nil {
+		speech["reprompt"] = r.Reprompt.OutputSpeech
+	}
+	for _, where := range []string{"speech", "reprompt"} {
+		text := speechText(speech[where])
+		for _, p := range patterns {
+			if m := p.FindString(text); m != "" {
+				violations = append(violations, ComplianceViolation{RulePersonalDataRequest, fmt.Sprintf("%s asks %q", where, m)})
+			}
+		}
+	}
+
+	if len(violations) > 0 {
+		return &ComplianceError{Violations: violations}
+	}
+	return nil
+}
+
+// shoppingTasks are the Connections tasks of Alexa Shopping Actions.
+var shoppingTasks = []string{"AMAZON.AddToShoppingCart", "AMAZON.BuyShoppingProducts"}
+
+// purchaseDirective describes an in-skill purchasing request or a shopping
+// task.
+func purchaseDirective(d interface{}) (string, bool) {
+	var c ConnectionsDirective
+	switch t := d.(type) {
+	case ConnectionsDirective:
+		c = t
+	case *ConnectionsDirective:
+		c = *t
+	default:
+		return "", false
+	}
+	if c.Type == "Connections.StartConnection" {
+		for _, task := range shoppingTasks {
+			if strings.HasPrefix(c.URI, "connection://"+task+"/") {
+				return c.Type + " " + task, true
+			}
+		}
+		return "", false
+	}
+	if c.Type != "Connections.SendRequest" {
+		return "", false
+	}
+	switch c.Name {
+	case "Buy", "Upsell", "Cancel":
+		return c.Type + " " + c.Name, true
+	}
+	return "", false
+}
+
+var ssmlTag = regexp.MustCompile(`<[^>]*>`)
+
+// speechText returns the words of speech, without SSML tags.
+func speechText(speech *OutputSpeech) string {
+	if speech == nil {
+		return ""
+	}
+	text := speech.Text
+	if speech.SSML != "" {
+		text = ssmlTag.ReplaceAllString(speech.SSML, " ")
+	}
+	return strings.Join(strings.Fields(text), " ")
+}
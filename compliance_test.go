@@ -0,0 +1,91 @@
+package alexa
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+)
+
+type complianceHandler struct {
+	emptyRequestHandler
+	respond func(*Response)
+}
+
+func (h *complianceHandler) OnIntent(ctx context.Context, request *Request, session *Session, aContext *Context, response *Response) error {
+	h.respond(response)
+	return nil
+}
+
+func validateKidResponse(k *KidCompliance, respond func(*Response)) error {
+	alexa := getAlexaWithHandler(&complianceHandler{respond: respond})
+	alexa.ResponseValidators = []ResponseValidator{k}
+	_, err := alexa.ProcessRequest(context.Background(), createRecipeRequest())
+	return err
+}
+
+func complianceRules(err error) []string {
+	var ce *ComplianceError
+	if !errors.As(err, &ce) {
+		return nil
+	}
+	var rules []string
+	for _, v := range ce.Violations {
+		rules = append(rules, v.Rule)
+	}
+	return rules
+}
+
+func TestKidComplianceCards(t *testing.T) {
+	err := validateKidResponse(&KidCompliance{}, func(r *Response) { r.SetLinkAccountCard() })
+	if !errors.Is(err, ErrComplianceViolation) {
+		t.Fatal("Expected ErrComplianceViolation but got", err)
+	}
+	if rules := complianceRules(err); len(rules) != 1 || rules[0] != RuleLinkAccountCard {
+		t.Error("Expected link account violation but were", rules)
+	}
+
+	err = validateKidResponse(&KidCompliance{}, func(r *Response) {
+		r.SetAskForPermissionsConsentCard([]string{"read::alexa:device:all:address"})
+	})
+	if rules := complianceRules(err); len(rules) != 1 || rules[0] != RulePermissionsCard {
+		t.Error("Expected permissions card violation but were", rules)
+	}
+}
+
+func TestKidCompliancePurchases(t *testing.T) {
+	buy := func(r *Response) {
+		r.AddConnectionsSendRequest("Buy", map[string]interface{}{"InSkillProduct": map[string]string{"productId": "amzn1.adg.product.x"}}, "token")
+	}
+	if rules := complianceRules(validateKidResponse(&KidCompliance{}, buy)); len(rules) != 1 || rules[0] != RulePurchaseDirective {
+		t.Error("Expected purchase violation but were", rules)
+	}
+	if err := validateKidResponse(&KidCompliance{AllowPurchases: true}, buy); err != nil {
+		t.Error("Expected purchases to be allowed but got", err)
+	}
+	addToCart := func(r *Response) {
+		r.AddConnectionsStartConnection("connection://AMAZON.AddToShoppingCart/1", map[string]interface{}{"products": []map[string]string{{"asin": "B000000001"}}}, "token")
+	}
+	if rules := complianceRules(validateKidResponse(&KidCompliance{}, addToCart)); len(rules) != 1 || rules[0] != RulePurchaseDirective {
+		t.Error("Expected shopping violation but were", rules)
+	}
+}
+
+func TestKidCompliancePersonalData(t *testing.T) {
+	err := validateKidResponse(&KidCompliance{}, func(r *Response) {
+		r.SetOutputSSML("<speak>Hi! What's your <emphasis>name</emphasis>?</speak>")
+		r.SetRepromptText("Tell me your home address.")
+	})
+	if rules := complianceRules(err); len(rules) != 2 {
+		t.Error("Expected speech and reprompt violations but were", err)
+	}
+
+	if err := validateKidResponse(&KidCompliance{}, func(r *Response) { r.SetOutputText("What is your favorite color?") }); err != nil {
+		t.Error("Expected no violation but got", err)
+	}
+
+	custom := &KidCompliance{PersonalDataPatterns: []*regexp.Regexp{regexp.MustCompile(`(?i)which school`)}}
+	if err := validateKidResponse(custom, func(r *Response) { r.SetOutputText("Which school do you go to?") }); err == nil {
+		t.Error("Expected custom pattern to be checked.")
+	}
+}
@@ -0,0 +1,126 @@
+package alexa
+
+import (
+	"context"
+	"log"
+)
+
+type requestEnvelopeKey struct{}
+type servicesKey struct{}
+
+// services are the per-request helpers stored in the context by
+// ProcessRequest.
+type services struct {
+	logger        *log.Logger
+	serviceClient func() *ServiceClient
+}
+
+// WithRequestEnvelope returns a copy of ctx carrying requestEnv. ProcessRequest
+// and Handler do this before calling the RequestHandler or middleware.
+func WithRequestEnvelope(ctx context.Context, requestEnv *RequestEnvelope) context.Context {
+	return context.WithValue(ctx, requestEnvelopeKey{}, requestEnv)
+}
+
+// withServices adds the per-request helpers for requestEnv to ctx.
+func (alexa *Alexa) withServices(ctx context.Context, requestEnv *RequestEnvelope) context.Context {
+	prefix := ""
+	if requestEnv.Request != nil && requestEnv.Request.RequestID != "" {
+		prefix = requestEnv.Request.RequestID + " "
+	}
+	s := &services{
+		logger: log.New(log.Writer(), prefix, log.Flags()),
+		serviceClient: func() *ServiceClient {
+			if requestEnv.Context == nil {
+				return nil
+			}
+			if alexa.ServiceClientFactory != nil {
+				return alexa.ServiceClientFactory(requestEnv.Context)
+			}
+			return NewServiceClient(requestEnv.Context)
+		},
+	}
+	return context.WithValue(WithRequestEnvelope(ctx, requestEnv), servicesKey{}, s)
+}
+
+// RequestEnvelopeFromContext returns the request envelope being handled, or
+// nil.
+func RequestEnvelopeFromContext(ctx context.Context) *RequestEnvelope {
+	requestEnv, _ := ctx.Value(requestEnvelopeKey{}).(*RequestEnvelope)
+	return requestEnv
+}
+
+// RequestFromContext returns the request being handled, or nil.
+func RequestFromContext(ctx context.Context) *Request {
+	if requestEnv := RequestEnvelopeFromContext(ctx); requestEnv != nil {
+		return requestEnv.Request
+	}
+	return nil
+}
+
+// SessionFromContext returns the session of the request being handled, or
+// nil.
+func SessionFromContext(ctx context.Context) *Session {
+	if requestEnv := RequestEnvelopeFromContext(ctx); requestEnv != nil {
+		return requestEnv.Session
+	}
+	return nil
+}
+
+// LocaleFromContext returns the locale of the request being handled, or "".
+func LocaleFromContext(ctx context.Context) string {
+	if request := RequestFromContext(ctx); request != nil {
+		return request.Locale
+	}
+	return ""
+}
+
+// RequestIDFromContext returns the ID of the request being handled, or "".
+func RequestIDFromContext(ctx context.Context) string {
+	if request := RequestFromContext(ctx); request != nil {
+		return request.RequestID
+	}
+	return ""
+}
+
+// UserIDFromContext returns the user ID of the request being handled, or "".
+func UserIDFromContext(ctx context.Context) string {
+	requestEnv := RequestEnvelopeFromContext(ctx)
+	switch {
+	case requestEnv == nil:
+		return ""
+	case requestEnv.Context != nil && requestEnv.Context.System.User.UserID != "":
+		return requestEnv.Context.System.User.UserID
+	case requestEnv.Session != nil:
+		return requestEnv.Session.User.UserID
+	}
+	return ""
+}
+
+// AttributesFromContext returns the session attributes of the request being
+// handled. Changes are returned to Alexa in the response. It returns nil
+// outside ProcessRequest.
+func AttributesFromContext(ctx context.Context) map[string]interface{} {
+	if session := SessionFromContext(ctx); session != nil {
+		return session.Attributes.String
+	}
+	return nil
+}
+
+// LoggerFromContext returns a logger whose lines are prefixed with the ID of
+// the request being handled, or the standard logger outside ProcessRequest.
+func LoggerFromContext(ctx context.Context) *log.Logger {
+	if s, ok := ctx.Value(servicesKey{}).(*services); ok {
+		return s.logger
+	}
+	return log.Default()
+}
+
+// ServiceClientFromContext returns a ServiceClient for the Alexa API,
+// authorized for the request being handled, or nil outside ProcessRequest.
+// It is created by Alexa.ServiceClientFactory if set.
+func ServiceClientFromContext(ctx context.Context) *ServiceClient {
+	if s, ok := ctx.Value(servicesKey{}).(*services); ok {
+		return s.serviceClient()
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package alexa
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+type contextHandler struct {
+	emptyRequestHandler
+	locale, requestID, userID string
+	client                    *ServiceClient
+}
+
+func (h *contextHandler) OnIntent(ctx context.Context, request *Request, session *Session, aContext *Context, response *Response) error {
+	h.locale = LocaleFromContext(ctx)
+	h.requestID = RequestIDFromContext(ctx)
+	h.userID = UserIDFromContext(ctx)
+	h.client = ServiceClientFromContext(ctx)
+	AttributesFromContext(ctx)["visits"] = 1
+	LoggerFromContext(ctx).Println("handled")
+	return nil
+}
+
+func TestRequestFromContext(t *testing.T) {
+	var logBuf bytes.Buffer
+	log.SetOutput(&logBuf)
+	defer log.SetOutput(os.Stderr)
+
+	h := &contextHandler{}
+	alexa := getAlexaWithHandler(h)
+	request := createRecipeRequest()
+	request.Context.System.APIEndpoint = "https://api.amazonalexa.com"
+	request.Context.System.APIAccessToken = "api-token"
+	response, err := alexa.ProcessRequest(context.Background(), request)
+	if err != nil {
+		t.Fatal("Error processing request. " + err.Error())
+	}
+
+	if h.locale != request.Request.Locale || h.requestID != request.Request.RequestID || h.userID == "" {
+		t.Error("Expected request details from context but were", h.locale, h.requestID, h.userID)
+	}
+	if h.client == nil || h.client.AccessToken != "api-token" {
+		t.Error("Expected service client for the request but was", h.client)
+	}
+	if response.SessionAttributes["visits"] != 1 {
+		t.Error("Expected attribute set through context to be returned but were", response.SessionAttributes)
+	}
+	if !strings.Contains(logBuf.String(), request.Request.RequestID+" ") {
+		t.Error("Expected log to be prefixed with the request ID but was", logBuf.String())
+	}
+}
+
+func TestServiceClientFactory(t *testing.T) {
+	h := &contextHandler{}
+	alexa := getAlexaWithHandler(h)
+	alexa.ServiceClientFactory = func(aContext *Context) *ServiceClient {
+		return &ServiceClient{Endpoint: "http://localhost:9999", MaxRetries: 1}
+	}
+	alexa.ProcessRequest(context.Background(), createRecipeRequest())
+	if h.client == nil || h.client.Endpoint != "http://localhost:9999" {
+		t.Error("Expected client from the factory but was", h.client)
+	}
+}
+
+func TestContextAccessorsOutsideRequest(t *testing.T) {
+	ctx := context.Background()
+	if RequestFromContext(ctx) != nil || LocaleFromContext(ctx) != "" || AttributesFromContext(ctx) != nil || ServiceClientFromContext(ctx) != nil {
+		t.Error("Expected zero values outside a request.")
+	}
+	if LoggerFromContext(ctx) == nil {
+		t.Error("Expected the standard logger outside a request.")
+	}
+}
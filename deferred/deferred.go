@@ -0,0 +1,367 @@
+// Package deferred runs work that takes longer than Alexa waits for a
+// response, such as generating a report. The handler defers a task and
+// acknowledges at once; a worker runs the task from a Queue, keeping its
+// status in an alexa.PersistenceAdapter, and Notifiers tell the user it is
+// done. The result is spoken when the user next opens the skill.
+//
+//	func (s *Skill) ReportIntent(in *alexa.HandlerInput) error {
+//		if _, err := s.Tasks.Defer(in.Ctx, "report", in.SlotValue("Month")); err != nil {
+//			return err
+//		}
+//		in.Response.SetOutputText("I'll let you know when your report is ready.")
+//		return nil
+//	}
+//
+// Workers usually run in another process, reading a queue such as Amazon
+// SQS through an implementation of Queue. LocalQueue runs them in the same
+// process, for tests and local development.
+//
+// The tasks of a user are stored apart from the other attributes of the user,
+// under their own persistence key, so workers never overwrite what the skill
+// saves. A Manager serializes the updates to the tasks of each user, which
+// read and rewrite all of them. Managers in several processes sharing a
+// PersistenceAdapter can still lose task updates, unless the adapter makes
+// the writes conditional, for example on a version attribute in DynamoDB.
+package deferred
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	alexa "github.com/ericdaugherty/alexa-skills-kit-golang"
+)
+
+// DefaultKey prefixes the persistence key the tasks of a user are stored
+// under.
+const DefaultKey = "deferredTasks"
+
+// tasksAttribute holds the tasks in the attributes of their persistence key.
+const tasksAttribute = "tasks"
+
+var (
+	// ErrNoUser reports that a task was deferred outside of a request.
+	ErrNoUser = errors.New("deferred: no user in context")
+	// ErrUnknownKind reports that no Func handles the kind of a task.
+	ErrUnknownKind = errors.New("deferred: no worker for task kind")
+	// ErrNoTask reports that a user has no task with an ID.
+	ErrNoTask = errors.New("deferred: no such task")
+)
+
+// Status is the status of a Task.
+type Status string
+
+// Statuses of a Task.
+const (
+	StatusPending Status = "PENDING"
+	StatusRunning Status = "RUNNING"
+	StatusDone    Status = "DONE"
+	StatusFailed  Status = "FAILED"
+)
+
+// Task is a unit of deferred work for a user.
+type Task struct {
+	ID     string `json:"id"`
+	Kind   string `json:"kind"`
+	UserID string `json:"userId"`
+	Locale string `json:"locale,omitempty"`
+	// APIEndpoint of the request, used by Notifiers to find the region.
+	APIEndpoint string          `json:"apiEndpoint,omitempty"`
+	Input       json.RawMessage `json:"input,omitempty"`
+	Status      Status          `json:"status"`
+	// Result is the text to tell the user, set when the task is done.
+	Result   string    `json:"result,omitempty"`
+	Error    string    `json:"error,omitempty"`
+	Notified bool      `json:"notified,omitempty"`
+	Created  time.Time `json:"created"`
+	Updated  time.Time `json:"updated"`
+}
+
+// Finished reports whether the task is done or failed.
+func (t *Task) Finished() bool {
+	return t.Status == StatusDone || t.Status == StatusFailed
+}
+
+// DecodeInput decodes the input of the task into v.
+func (t *Task) DecodeInput(v interface{}) error {
+	return json.Unmarshal(t.Input, v)
+}
+
+// Func does the work of a task, returning the text to tell the user.
+type Func func(ctx context.Context, task *Task) (string, error)
+
+// Notifier tells the user that a task finished.
+type Notifier interface {
+	Notify(ctx context.Context, task *Task) error
+}
+
+// Manager defers tasks and runs them.
+type Manager struct {
+	Queue       Queue
+	Persistence alexa.PersistenceAdapter
+	// Workers maps the kind of a task to the Func doing its work.
+	Workers map[string]Func
+	// Notifiers are all called when a task finishes. Errors are logged.
+	Notifiers []Notifier
+	// Key prefixes the persistence key of the tasks of a user, followed by a
+	// slash and the user ID. Default value is DefaultKey.
+	Key string
+	Now func() time.Time
+
+	locksMu sync.Mutex
+	locks   map[string]*userLock
+}
+
+// userLock serializes the updates to the tasks of a user.
+type userLock struct {
+	sync.Mutex
+	// users counts the callers holding or waiting for the lock.
+	users int
+}
+
+// NewManager creates a Manager with no workers.
+func NewManager(queue Queue, persistence alexa.PersistenceAdapter) *Manager {
+	return &Manager{Queue: queue, Persistence: persistence, Workers: make(map[string]Func)}
+}
+
+// Handle sets the Func doing the work of tasks of kind.
+func (m *Manager) Handle(kind string, f Func) {
+	m.Workers[kind] = f
+}
+
+// Defer stores a pending task of kind with input, for the user of the request
+// in ctx, and adds it to the Queue.
+func (m *Manager) Defer(ctx context.Context, kind string, input interface{}) (*Task, error) {
+	requestEnv := alexa.RequestEnvelopeFromContext(ctx)
+	userID := alexa.UserIDFromContext(ctx)
+	if requestEnv == nil || userID == "" {
+		return nil, ErrNoUser
+	}
+	b, err := json.Marshal(input)
+	if err != nil {
+		return nil, err
+	}
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+
+	now := m.now()
+	task := &Task{
+		ID:      id,
+		Kind:    kind,
+		UserID:  userID,
+		Locale:  alexa.LocaleFromContext(ctx),
+		Input:   b,
+		Status:  StatusPending,
+		Created: now,
+		Updated: now,
+	}
+	if requestEnv.Context != nil {
+		task.APIEndpoint = requestEnv.Context.System.APIEndpoint
+	}
+	if err := m.save(ctx, task); err != nil {
+		return nil, err
+	}
+	if err := m.Queue.Enqueue(ctx, task); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// Process runs the Func for task, stores the result and notifies the user.
+// The error of the Func is stored in the task, not returned.
+func (m *Manager) Process(ctx context.Context, task *Task) error {
+	t := *task
+	t.Status = StatusRunning
+	t.Updated = m.now()
+	if err := m.save(ctx, &t); err != nil {
+		return err
+	}
+
+	f, ok := m.Workers[t.Kind]
+	var err error
+	if ok {
+		t.Result, err = f(ctx, &t)
+	} else {
+		err = ErrUnknownKind
+	}
+	t.Status = StatusDone
+	if err != nil {
+		log.Println("Error running deferred task", t.ID, t.Kind+".", err)
+		t.Status = StatusFailed
+		t.Error = err.Error()
+	}
+
+	for _, n := range m.Notifiers {
+		if err := n.Notify(ctx, &t); err != nil {
+			log.Println("Error notifying deferred task", t.ID+".", err)
+			continue
+		}
+		t.Notified = true
+	}
+	t.Updated = m.now()
+	return m.save(ctx, &t)
+}
+
+// Run processes tasks from the Queue until ctx is done or the Queue is
+// closed. Errors storing tasks are logged.
+func (m *Manager) Run(ctx context.Context) error {
+	for {
+		task, err := m.Queue.Dequeue(ctx)
+		if err != nil {
+			return err
+		}
+		if err := m.Process(ctx, task); err != nil {
+			log.Println("Error processing deferred task", task.ID+".", err)
+		}
+	}
+}
+
+// Task returns the task of the user with id.
+func (m *Manager) Task(ctx context.Context, userID, id string) (*Task, error) {
+	tasks, err := m.load(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	t, ok := tasks[id]
+	if !ok {
+		return nil, ErrNoTask
+	}
+	return t, nil
+}
+
+// Tasks returns the tasks of the user, oldest first.
+func (m *Manager) Tasks(ctx context.Context, userID string) ([]*Task, error) {
+	tasks, err := m.load(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return sorted(tasks), nil
+}
+
+// Collect returns the finished tasks of the user, oldest first, and forgets
+// them. Call it when the user opens the skill to tell them the results.
+func (m *Manager) Collect(ctx context.Context, userID string) ([]*Task, error) {
+	unlock := m.lock(userID)
+	defer unlock()
+	tasks, err := m.load(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	finished := make(map[string]*Task)
+	for id, t := range tasks {
+		if t.Finished() {
+			finished[id] = t
+			delete(tasks, id)
+		}
+	}
+	if len(finished) == 0 {
+		return nil, nil
+	}
+	if err := m.store(ctx, userID, tasks); err != nil {
+		return nil, err
+	}
+	return sorted(finished), nil
+}
+
+// save stores task with the other tasks of its user.
+func (m *Manager) save(ctx context.Context, task *Task) error {
+	unlock := m.lock(task.UserID)
+	defer unlock()
+	tasks, err := m.load(ctx, task.UserID)
+	if err != nil {
+		return err
+	}
+	tasks[task.ID] = task
+	return m.store(ctx, task.UserID, tasks)
+}
+
+// load returns the tasks of the user, by ID.
+func (m *Manager) load(ctx context.Context, userID string) (map[string]*Task, error) {
+	attributes, err := m.Persistence.GetAttributes(ctx, m.key(userID))
+	if err != nil {
+		return nil, err
+	}
+	tasks := make(map[string]*Task)
+	if _, err := alexa.DecodeAttribute(attributes, tasksAttribute, &tasks); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// store replaces the tasks of the user.
+func (m *Manager) store(ctx context.Context, userID string, tasks map[string]*Task) error {
+	return m.Persistence.SaveAttributes(ctx, m.key(userID), map[string]interface{}{tasksAttribute: tasks})
+}
+
+// lock locks the tasks of the user, returning the function unlocking them.
+// The lock is forgotten once no one holds or waits for it.
+func (m *Manager) lock(userID string) func() {
+	m.locksMu.Lock()
+	if m.locks == nil {
+		m.locks = make(map[string]*userLock)
+	}
+	l := m.locks[userID]
+	if l == nil {
+		l = &userLock{}
+		m.locks[userID] = l
+	}
+	l.users++
+	m.locksMu.Unlock()
+
+	l.Lock()
+	return func() {
+		l.Unlock()
+		m.locksMu.Lock()
+		l.users--
+		if l.users == 0 {
+			delete(m.locks, userID)
+		}
+		m.locksMu.Unlock()
+	}
+}
+
+// key returns the persistence key of the tasks of the user.
+func (m *Manager) key(userID string) string {
+	prefix := m.Key
+	if prefix == "" {
+		prefix = DefaultKey
+	}
+	return prefix + "/" + userID
+}
+
+func (m *Manager) now() time.Time {
+	if m.Now == nil {
+		return time.Now()
+	}
+	return m.Now()
+}
+
+func sorted(tasks map[string]*Task) []*Task {
+	s := make([]*Task, 0, len(tasks))
+	for _, t := range tasks {
+		s = append(s, t)
+	}
+	sort.Slice(s, func(i, j int) bool {
+		if s[i].Created.Equal(s[j].Created) {
+			return s[i].ID < s[j].ID
+		}
+		return s[i].Created.Before(s[j].Created)
+	})
+	return s
+}
+
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,242 @@
+package deferred
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	alexa "github.com/ericdaugherty/alexa-skills-kit-golang"
+)
+
+func requestContext(userID string) context.Context {
+	requestEnv := &alexa.RequestEnvelope{
+		Session: &alexa.Session{},
+		Request: &alexa.Request{Type: "IntentRequest", Locale: "en-GB"},
+		Context: &alexa.Context{},
+	}
+	requestEnv.Session.User.UserID = userID
+	requestEnv.Context.System.User.UserID = userID
+	return alexa.WithRequestEnvelope(context.Background(), requestEnv)
+}
+
+func TestManager(t *testing.T) {
+	ctx := requestContext("amzn1.ask.account.1")
+	queue := NewLocalQueue(10)
+	m := NewManager(queue, alexa.NewMemoryPersistence())
+	m.Handle("report", func(ctx context.Context, task *Task) (string, error) {
+		var month string
+		task.DecodeInput(&month)
+		if month == "" {
+			return "", errors.New("no month")
+		}
+		return "Your " + month + " report is ready.", nil
+	})
+
+	report, err := m.Defer(ctx, "report", "March")
+	if err != nil {
+		t.Fatal("Error deferring task. " + err.Error())
+	}
+	failing, _ := m.Defer(ctx, "report", "")
+	if _, err := m.Defer(context.Background(), "report", "March"); err != ErrNoUser {
+		t.Error("Expected ErrNoUser outside a request but got", err)
+	}
+
+	if task, _ := m.Task(ctx, "amzn1.ask.account.1", report.ID); task.Status != StatusPending || task.Locale != "en-GB" {
+		t.Error("Expected a pending task but was", task)
+	}
+	if tasks, _ := m.Collect(ctx, "amzn1.ask.account.1"); len(tasks) != 0 {
+		t.Error("Expected no finished tasks before the worker ran but were", tasks)
+	}
+
+	for queue.Len() > 0 {
+		task, _ := queue.Dequeue(ctx)
+		if err := m.Process(ctx, task); err != nil {
+			t.Fatal("Error processing task. " + err.Error())
+		}
+	}
+
+	tasks, err := m.Collect(ctx, "amzn1.ask.account.1")
+	if err != nil || len(tasks) != 2 {
+		t.Fatal("Expected two finished tasks but were", tasks, err)
+	}
+	for _, task := range tasks {
+		switch task.ID {
+		case report.ID:
+			if task.Status != StatusDone || task.Result != "Your March report is ready." {
+				t.Error("Expected the report to be done but was", task)
+			}
+		case failing.ID:
+			if task.Status != StatusFailed || task.Error != "no month" {
+				t.Error("Expected the task to fail but was", task)
+			}
+		}
+	}
+	if _, err := m.Task(ctx, "amzn1.ask.account.1", report.ID); err != ErrNoTask {
+		t.Error("Expected collected tasks to be forgotten but got", err)
+	}
+}
+
+func TestManagerRun(t *testing.T) {
+	ctx := requestContext("amzn1.ask.account.1")
+	queue := NewLocalQueue(1)
+	m := NewManager(queue, alexa.NewMemoryPersistence())
+	done := make(chan string)
+	m.Handle("stores", func(ctx context.Context, task *Task) (string, error) {
+		done <- task.ID
+		return "Three stores have it.", nil
+	})
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	var runErr error
+	go func() {
+		defer wg.Done()
+		runErr = m.Run(context.Background())
+	}()
+
+	task, _ := m.Defer(ctx, "stores", nil)
+	if id := <-done; id != task.ID {
+		t.Error("Expected the worker to run the task but ran", id)
+	}
+	queue.Close()
+	wg.Wait()
+	if runErr != ErrQueueClosed {
+		t.Error("Expected Run to stop when the queue closed but got", runErr)
+	}
+	if tasks, _ := m.Tasks(ctx, "amzn1.ask.account.1"); len(tasks) != 1 || tasks[0].Status != StatusDone {
+		t.Error("Expected the task to be done but tasks were", tasks)
+	}
+}
+
+// slowPersistence widens the window between reading and saving attributes.
+type slowPersistence struct {
+	*alexa.MemoryPersistence
+}
+
+func (p slowPersistence) GetAttributes(ctx context.Context, key string) (map[string]interface{}, error) {
+	attributes, err := p.MemoryPersistence.GetAttributes(ctx, key)
+	time.Sleep(time.Millisecond)
+	return attributes, err
+}
+
+func TestManagerConcurrentUpdates(t *testing.T) {
+	ctx := requestContext("amzn1.ask.account.1")
+	queue := NewLocalQueue(20)
+	m := NewManager(queue, slowPersistence{alexa.NewMemoryPersistence()})
+	m.Handle("report", func(ctx context.Context, task *Task) (string, error) {
+		return "Done.", nil
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.Defer(ctx, "report", i)
+		}()
+	}
+	wg.Wait()
+	for queue.Len() > 0 {
+		task, _ := queue.Dequeue(ctx)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.Process(ctx, task)
+		}()
+	}
+	wg.Wait()
+
+	if tasks, _ := m.Collect(ctx, "amzn1.ask.account.1"); len(tasks) != 20 {
+		t.Error("Expected 20 finished tasks but were", len(tasks))
+	}
+	if len(m.locks) != 0 {
+		t.Error("Expected the locks to be forgotten but were", m.locks)
+	}
+}
+
+func TestManagerKeepsUserAttributes(t *testing.T) {
+	ctx := requestContext("amzn1.ask.account.1")
+	persistence := alexa.NewMemoryPersistence()
+	m := NewManager(NewLocalQueue(1), persistence)
+	task, _ := m.Defer(ctx, "report", nil)
+
+	// The skill saves the attributes of the user while the task runs.
+	persistence.SaveAttributes(ctx, "amzn1.ask.account.1", map[string]interface{}{"failures": 3})
+	m.Process(ctx, task)
+
+	attributes, _ := persistence.GetAttributes(ctx, "amzn1.ask.account.1")
+	if attributes["failures"] != float64(3) || len(attributes) != 1 {
+		t.Error("Expected the attributes of the user to be kept but were", attributes)
+	}
+	if tasks, _ := m.Collect(ctx, "amzn1.ask.account.1"); len(tasks) != 1 {
+		t.Error("Expected the task to be stored apart but tasks were", tasks)
+	}
+}
+
+func TestNotifiers(t *testing.T) {
+	var mu sync.Mutex
+	paths := make(map[string]map[string]interface{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			w.Write([]byte(`{"access_token":"Atc|abc","expires_in":3600,"token_type":"bearer"}`))
+			return
+		}
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		paths[r.URL.Path] = body
+		mu.Unlock()
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+	region := alexa.Region{Name: "test", APIEndpoint: server.URL, TokenEndpoint: server.URL + "/token"}
+
+	ctx := requestContext("amzn1.ask.account.1")
+	queue := NewLocalQueue(1)
+	m := NewManager(queue, alexa.NewMemoryPersistence())
+	m.Handle("report", func(ctx context.Context, task *Task) (string, error) { return "Done.", nil })
+	m.Notifiers = []Notifier{
+		&MessageAlert{Tokens: &alexa.LWATokenSource{ClientID: "id", ClientSecret: "secret", Region: region}, Region: region, Creator: "Reports"},
+		&SkillMessage{Tokens: &alexa.LWATokenSource{ClientID: "id", ClientSecret: "secret", Region: region}, Region: region},
+	}
+
+	task, _ := m.Defer(ctx, "report", nil)
+	queued, _ := queue.Dequeue(ctx)
+	if err := m.Process(ctx, queued); err != nil {
+		t.Fatal("Error processing task. " + err.Error())
+	}
+
+	event := paths["/v1/proactiveEvents/stages/development"]
+	if event == nil || event["referenceId"] != task.ID {
+		t.Error("Expected a proactive event for the task but was", event)
+	}
+	message := paths["/v1/skillmessages/users/amzn1.ask.account.1"]
+	if data, _ := message["data"].(map[string]interface{}); data == nil || data["taskId"] != task.ID || data["status"] != "DONE" {
+		t.Error("Expected a skill message for the task but was", message)
+	}
+	if stored, _ := m.Task(ctx, "amzn1.ask.account.1", task.ID); !stored.Notified {
+		t.Error("Expected the task to be marked notified")
+	}
+}
+
+func TestLocalQueueClosed(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	q := NewLocalQueue(1)
+	q.Enqueue(ctx, &Task{ID: "1"})
+	q.Close()
+	if err := q.Enqueue(ctx, &Task{ID: "2"}); err != ErrQueueClosed {
+		t.Error("Expected ErrQueueClosed but got", err)
+	}
+	if task, err := q.Dequeue(ctx); err != nil || task.ID != "1" {
+		t.Error("Expected the waiting task after close but got", task, err)
+	}
+	if _, err := q.Dequeue(ctx); err != ErrQueueClosed {
+		t.Error("Expected ErrQueueClosed but got", err)
+	}
+}
@@ -0,0 +1,100 @@
+package deferred
+
+import (
+	"context"
+	"time"
+
+	alexa "github.com/ericdaugherty/alexa-skills-kit-golang"
+)
+
+// MessageAlert is a Notifier sending an AMAZON.MessageAlert.Activated
+// proactive event, so the device tells the user they have a new message from
+// Creator. The user must have subscribed to the event in the Alexa app.
+type MessageAlert struct {
+	// Tokens needs the alexa::proactive_events scope.
+	Tokens *alexa.LWATokenSource
+	// Region is used for tasks with no known API endpoint. Default is the
+	// region of the locale of the task.
+	Region  alexa.Region
+	Creator string
+	// Live sends events to the live skill, not the development stage.
+	Live bool
+	// Expiry of the alert. Default value is 24 hours.
+	Expiry time.Duration
+	Now    func() time.Time
+}
+
+// Notify sends the alert to the user of task.
+func (n *MessageAlert) Notify(ctx context.Context, task *Task) error {
+	token, err := n.Tokens.Token(ctx)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if n.Now != nil {
+		now = n.Now()
+	}
+	expiry := n.Expiry
+	if expiry == 0 {
+		expiry = 24 * time.Hour
+	}
+
+	event := &alexa.ProactiveEvent{Timestamp: now, ReferenceID: task.ID, ExpiryTime: now.Add(expiry)}
+	event.Event.Name = "AMAZON.MessageAlert.Activated"
+	event.Event.Payload = map[string]interface{}{
+		"state": map[string]string{"status": "UNREAD", "freshness": "NEW"},
+		"messageGroup": map[string]interface{}{
+			"creator": map[string]string{"name": n.Creator},
+			"count":   1,
+		},
+	}
+	event.RelevantAudience.Type = "Unicast"
+	event.RelevantAudience.Payload = map[string]string{"user": task.UserID}
+
+	client := alexa.NewServiceClientForRegion(region(n.Region, task), token)
+	return client.SendProactiveEvent(ctx, event, n.Live)
+}
+
+// SkillMessage is a Notifier sending a skill message with the ID, kind and
+// status of the task, received by the skill as a Messaging.MessageReceived
+// request, for example to update a list or send a notification of its own.
+type SkillMessage struct {
+	// Tokens needs the alexa:skill_messaging scope.
+	Tokens *alexa.LWATokenSource
+	// Region is used for tasks with no known API endpoint. Default is the
+	// region of the locale of the task.
+	Region alexa.Region
+	// ExpiresAfter is how long Alexa tries to deliver the message. Default
+	// value is one hour.
+	ExpiresAfter time.Duration
+}
+
+// Notify sends the message for the user of task.
+func (n *SkillMessage) Notify(ctx context.Context, task *Task) error {
+	token, err := n.Tokens.Token(ctx)
+	if err != nil {
+		return err
+	}
+	expiresAfter := n.ExpiresAfter
+	if expiresAfter == 0 {
+		expiresAfter = time.Hour
+	}
+	data := map[string]string{"taskId": task.ID, "kind": task.Kind, "status": string(task.Status)}
+
+	client := alexa.NewServiceClientForRegion(region(n.Region, task), token)
+	return client.SendSkillMessage(ctx, task.UserID, data, expiresAfter)
+}
+
+// region returns the region of the API endpoint of task, or r, or the region
+// of the locale of task.
+func region(r alexa.Region, task *Task) alexa.Region {
+	if task.APIEndpoint != "" {
+		if found, ok := alexa.RegionForAPIEndpoint(task.APIEndpoint); ok {
+			return found
+		}
+	}
+	if r.APIEndpoint != "" {
+		return r
+	}
+	return alexa.RegionForLocale(task.Locale)
+}
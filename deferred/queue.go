@@ -0,0 +1,75 @@
+package deferred
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrQueueClosed reports that a Queue was closed.
+var ErrQueueClosed = errors.New("deferred: queue closed")
+
+// Queue carries tasks from the handler to the workers.
+type Queue interface {
+	Enqueue(ctx context.Context, task *Task) error
+	// Dequeue waits for the next task.
+	Dequeue(ctx context.Context) (*Task, error)
+}
+
+// LocalQueue is a Queue in memory, for workers in the same process.
+type LocalQueue struct {
+	tasks     chan *Task
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewLocalQueue creates a LocalQueue holding up to size tasks.
+func NewLocalQueue(size int) *LocalQueue {
+	return &LocalQueue{tasks: make(chan *Task, size), done: make(chan struct{})}
+}
+
+// Enqueue adds a copy of task, waiting while the queue is full.
+func (q *LocalQueue) Enqueue(ctx context.Context, task *Task) error {
+	t := *task
+	select {
+	case <-q.done:
+		return ErrQueueClosed
+	default:
+	}
+	select {
+	case q.tasks <- &t:
+		return nil
+	case <-q.done:
+		return ErrQueueClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Dequeue waits for the next task. Tasks left when the queue is closed are
+// still returned.
+func (q *LocalQueue) Dequeue(ctx context.Context) (*Task, error) {
+	select {
+	case t := <-q.tasks:
+		return t, nil
+	default:
+	}
+	select {
+	case t := <-q.tasks:
+		return t, nil
+	case <-q.done:
+		return nil, ErrQueueClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Close stops the queue. Workers waiting in Dequeue return ErrQueueClosed.
+func (q *LocalQueue) Close() {
+	q.closeOnce.Do(func() { close(q.done) })
+}
+
+// Len returns the number of tasks waiting.
+func (q *LocalQueue) Len() int {
+	return len(q.tasks)
+}
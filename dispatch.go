@@ -0,0 +1,221 @@
+package alexa
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// ErrUnhandledIntent reports that a Dispatcher has no method for an intent.
+var ErrUnhandledIntent = errors.New("no method for intent")
+
+// HandlerInput is passed to the methods called by a Dispatcher.
+type HandlerInput struct {
+	Ctx      context.Context
+	Request  *Request
+	Session  *Session
+	Context  *Context
+	Response *Response
+}
+
+// SlotValue returns the value of the named slot of the intent, or "".
+func (in *HandlerInput) SlotValue(name string) string {
+	return in.Request.Intent.Slots[name].Value
+}
+
+// BaseSkill can be embedded in a skill used with a Dispatcher. It provides
+// the Launch, SessionStarted, SessionEnded and Unhandled methods, which the
+// skill can override.
+type BaseSkill struct{}
+
+// Launch handles a LaunchRequest. It does nothing.
+func (BaseSkill) Launch(in *HandlerInput) error { return nil }
+
+// SessionStarted is called for the first request of a session. It does nothing.
+func (BaseSkill) SessionStarted(in *HandlerInput) error { return nil }
+
+// SessionEnded handles a SessionEndedRequest. It does nothing.
+func (BaseSkill) SessionEnded(in *HandlerInput) error { return nil }
+
+// Unhandled is called for an intent with no method. It returns
+// ErrUnhandledIntent.
+func (BaseSkill) Unhandled(in *HandlerInput) error { return ErrUnhandledIntent }
+
+type launchHandler interface {
+	Launch(in *HandlerInput) error
+}
+
+type sessionStartedHandler interface {
+	SessionStarted(in *HandlerInput) error
+}
+
+type sessionEndedHandler interface {
+	SessionEnded(in *HandlerInput) error
+}
+
+type sessionResumedHandler interface {
+	SessionResumed(in *HandlerInput) error
+}
+
+type unhandledHandler interface {
+	Unhandled(in *HandlerInput) error
+}
+
+type intentMethod func(in *HandlerInput) error
+
+// Dispatcher is a RequestHandler that calls a method of a skill named after
+// the intent, such as
+//
+//	func (s *Skill) RecipeIntent(in *alexa.HandlerInput) error
+//
+// Intent names are converted with IntentMethodName, so AMAZON.HelpIntent is
+// handled by AmazonHelpIntent, or else have their dots replaced by
+// underscores, as in AMAZON_HelpIntent. Names need not end in Intent: a
+// GetRecipe intent is handled by GetRecipe. Methods are found once, by
+// NewDispatcher.
+// SessionResumedRequests are passed to a SessionResumed method, if the skill
+// has one.
+type Dispatcher struct {
+	skill    interface{}
+	methods  map[string]intentMethod
+	problems []string
+}
+
+// dispatcherMethods are the methods of a skill that handle requests other
+// than intents.
+var dispatcherMethods = map[string]bool{
+	"Launch": true, "SessionStarted": true, "SessionEnded": true, "SessionResumed": true, "Unhandled": true,
+}
+
+// NewDispatcher creates a Dispatcher for the methods of skill. Exported
+// methods named after an intent of models, or ending in Intent, whose
+// signature does not match, and intents of models with no method, are logged
+// and reported by Problems.
+func NewDispatcher(skill interface{}, models ...*InteractionModel) *Dispatcher {
+	d := &Dispatcher{skill: skill, methods: make(map[string]intentMethod)}
+
+	intents := make(map[string]bool)
+	for _, model := range models {
+		for _, intent := range model.InteractionModel.LanguageModel.Intents {
+			for _, name := range methodNames(intent.Name) {
+				intents[name] = true
+			}
+		}
+	}
+
+	v := reflect.ValueOf(skill)
+	t := v.Type()
+	for i := 0; i < t.NumMethod(); i++ {
+		name := t.Method(i).Name
+		if dispatcherMethods[name] {
+			continue
+		}
+		m := v.Method(i)
+		if f, ok := m.Interface().(func(*HandlerInput) error); ok {
+			d.methods[name] = f
+			continue
+		}
+		if intents[name] || strings.HasSuffix(name, "Intent") {
+			d.problems = append(d.problems, fmt.Sprintf("method %s has signature %s, expected func(*alexa.HandlerInput) error", name, m.Type()))
+		}
+	}
+
+	for _, model := range models {
+		for _, intent := range model.InteractionModel.LanguageModel.Intents {
+			if _, ok := d.method(intent.Name); !ok {
+				d.problems = append(d.problems, fmt.Sprintf("intent %s has no method %s", intent.Name, IntentMethodName(intent.Name)))
+			}
+		}
+	}
+
+	sort.Strings(d.problems)
+	for _, p := range d.problems {
+		log.Println("Dispatcher:", p)
+	}
+	return d
+}
+
+// Problems returns the problems found by NewDispatcher.
+func (d *Dispatcher) Problems() []string {
+	return d.problems
+}
+
+// method returns the method handling intent.
+func (d *Dispatcher) method(intent string) (intentMethod, bool) {
+	for _, name := range methodNames(intent) {
+		if m, ok := d.methods[name]; ok {
+			return m, true
+		}
+	}
+	return nil, false
+}
+
+// methodNames returns the names of the methods that may handle intent.
+func methodNames(intent string) []string {
+	return []string{IntentMethodName(intent), strings.ReplaceAll(intent, ".", "_")}
+}
+
+// IntentMethodName returns the name of the method handling intent. Names are
+// split at dots and underscores, and each part is capitalized, with parts in
+// all capitals converted to title case: AMAZON.HelpIntent is AmazonHelpIntent.
+func IntentMethodName(intent string) string {
+	var b strings.Builder
+	for _, part := range strings.FieldsFunc(intent, func(r rune) bool { return r == '.' || r == '_' }) {
+		if strings.ToUpper(part) == part {
+			part = strings.ToLower(part)
+		}
+		r := []rune(part)
+		r[0] = unicode.ToUpper(r[0])
+		b.WriteString(string(r))
+	}
+	return b.String()
+}
+
+// OnSessionStarted calls SessionStarted, if the skill has it.
+func (d *Dispatcher) OnSessionStarted(ctx context.Context, request *Request, session *Session, aContext *Context, response *Response) error {
+	if h, ok := d.skill.(sessionStartedHandler); ok {
+		return h.SessionStarted(&HandlerInput{ctx, request, session, aContext, response})
+	}
+	return nil
+}
+
+// OnLaunch calls Launch, if the skill has it.
+func (d *Dispatcher) OnLaunch(ctx context.Context, request *Request, session *Session, aContext *Context, response *Response) error {
+	if h, ok := d.skill.(launchHandler); ok {
+		return h.Launch(&HandlerInput{ctx, request, session, aContext, response})
+	}
+	return nil
+}
+
+// OnIntent calls the method for the intent, or Unhandled if there is none.
+func (d *Dispatcher) OnIntent(ctx context.Context, request *Request, session *Session, aContext *Context, response *Response) error {
+	in := &HandlerInput{ctx, request, session, aContext, response}
+	if m, ok := d.method(request.Intent.Name); ok {
+		return m(in)
+	}
+	if h, ok := d.skill.(unhandledHandler); ok {
+		return h.Unhandled(in)
+	}
+	return ErrUnhandledIntent
+}
+
+// OnSessionEnded calls SessionEnded, if the skill has it.
+func (d *Dispatcher) OnSessionEnded(ctx context.Context, request *Request, session *Session, aContext *Context, response *Response) error {
+	if h, ok := d.skill.(sessionEndedHandler); ok {
+		return h.SessionEnded(&HandlerInput{ctx, request, session, aContext, response})
+	}
+	return nil
+}
+
+// OnSessionResumed calls SessionResumed, if the skill has it.
+func (d *Dispatcher) OnSessionResumed(ctx context.Context, request *Request, session *Session, aContext *Context, response *Response) error {
+	if h, ok := d.skill.(sessionResumedHandler); ok {
+		return h.SessionResumed(&HandlerInput{ctx, request, session, aContext, response})
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package alexa
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+type dispatchSkill struct {
+	BaseSkill
+	launched bool
+	recipe   string
+}
+
+func (s *dispatchSkill) Launch(in *HandlerInput) error {
+	s.launched = true
+	return nil
+}
+
+func (s *dispatchSkill) RecipeIntent(in *HandlerInput) error {
+	s.recipe = in.SlotValue("Item")
+	in.Response.SetOutputText("Recipe for " + s.recipe)
+	return nil
+}
+
+func (s *dispatchSkill) AmazonHelpIntent(in *HandlerInput) error {
+	in.Response.SetOutputText("Help")
+	return nil
+}
+
+func (s *dispatchSkill) GetRecipe(in *HandlerInput) error {
+	in.Response.SetOutputText("Recipes")
+	return nil
+}
+
+func (s *dispatchSkill) AMAZON_CancelIntent(in *HandlerInput) error {
+	in.Response.SetOutputText("Cancelled")
+	return nil
+}
+
+func (s *dispatchSkill) Search(query string) error {
+	return nil
+}
+
+func (s *dispatchSkill) BrokenIntent(request *Request) error {
+	return nil
+}
+
+func TestIntentMethodName(t *testing.T) {
+	names := map[string]string{
+		"AMAZON.HelpIntent":         "AmazonHelpIntent",
+		"AMAZON.NavigateHomeIntent": "AmazonNavigateHomeIntent",
+		"RecipeIntent":              "RecipeIntent",
+		"get_recipe_Intent":         "GetRecipeIntent",
+	}
+	for intent, expected := range names {
+		if name := IntentMethodName(intent); name != expected {
+			t.Errorf("Expected %s to be %s but was %s", intent, expected, name)
+		}
+	}
+}
+
+func TestDispatcher(t *testing.T) {
+	skill := &dispatchSkill{}
+	d := NewDispatcher(skill)
+	alexa := getAlexaWithHandler(d)
+
+	request := createRecipeRequest()
+	request.Request.Intent.Slots = map[string]IntentSlot{"Item": {Name: "Item", Value: "snowball"}}
+	response, err := alexa.ProcessRequest(context.Background(), request)
+	if err != nil {
+		t.Fatal("Error processing request. " + err.Error())
+	}
+	if skill.recipe != "snowball" || response.Response.OutputSpeech.Text != "Recipe for snowball" {
+		t.Error("Expected RecipeIntent to be called but response was", response.Response.OutputSpeech)
+	}
+
+	request = createRecipeRequest()
+	request.Request.Intent.Name = "AMAZON.HelpIntent"
+	response, _ = alexa.ProcessRequest(context.Background(), request)
+	if response.Response.OutputSpeech.Text != "Help" {
+		t.Error("Expected AmazonHelpIntent to be called but response was", response.Response.OutputSpeech)
+	}
+
+	for intent, expected := range map[string]string{"GetRecipe": "Recipes", "AMAZON.CancelIntent": "Cancelled"} {
+		request = createRecipeRequest()
+		request.Request.Intent.Name = intent
+		response, _ = alexa.ProcessRequest(context.Background(), request)
+		if response.Response.OutputSpeech.Text != expected {
+			t.Error("Expected the method for "+intent+" to be called but response was", response.Response.OutputSpeech)
+		}
+	}
+
+	request = createRecipeRequest()
+	request.Request.Type = launchRequestName
+	alexa.ProcessRequest(context.Background(), request)
+	if !skill.launched {
+		t.Error("Expected Launch to be called.")
+	}
+
+	request = createRecipeRequest()
+	request.Request.Intent.Name = "UnknownIntent"
+	if _, err := alexa.ProcessRequest(context.Background(), request); err != ErrUnhandledIntent {
+		t.Error("Expected ErrUnhandledIntent but got", err)
+	}
+}
+
+func TestDispatcherProblems(t *testing.T) {
+	model := &InteractionModel{}
+	model.InteractionModel.LanguageModel.Intents = []ModelIntent{
+		{Name: "RecipeIntent"}, {Name: "AMAZON.HelpIntent"}, {Name: "AMAZON.StopIntent"},
+		{Name: "GetRecipe"}, {Name: "AMAZON.CancelIntent"}, {Name: "Search"},
+	}
+	problems := NewDispatcher(&dispatchSkill{}, model).Problems()
+	if len(problems) != 4 {
+		t.Fatal("Expected 4 problems but were", problems)
+	}
+	if !strings.Contains(problems[0], "AMAZON.StopIntent") || !strings.Contains(problems[1], "Search") ||
+		!strings.Contains(problems[2], "BrokenIntent") || !strings.Contains(problems[3], "Search") {
+		t.Error("Expected the missing methods and bad signatures to be reported but were", problems)
+	}
+}
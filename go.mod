@@ -0,0 +1,5 @@
+module github.com/ericdaugherty/alexa-skills-kit-golang
+
+go 1.24
+
+require github.com/aws/aws-lambda-go v1.49.0
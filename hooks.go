@@ -0,0 +1,84 @@
+package alexa
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+const defaultPostResponseHookTimeout = 500 * time.Millisecond
+
+// postResponseHookMargin is reserved from the context deadline so that a
+// Lambda invocation can still return the response after the hooks time out.
+const postResponseHookMargin = 250 * time.Millisecond
+
+// PostResponseHook is called with the request and the final ResponseEnvelope
+// after the response has been computed, for work such as analytics flushes,
+// cache warming or audit writes. Hooks run concurrently and share the request
+// and response, which they must treat as read-only. Errors and panics are
+// logged and never affect the response sent to Alexa.
+//
+// If Alexa has SensitiveSlots, hooks receive copies of the request and
+// response with their values masked.
+type PostResponseHook func(ctx context.Context, requestEnv *RequestEnvelope, responseEnv *ResponseEnvelope) error
+
+// runPostResponseHooks runs the hooks and waits for them for
+// PostResponseHookTimeout, or until the deadline of ctx, less
+// postResponseHookMargin, if it is earlier. Hooks still running at that point
+// are abandoned.
+func (alexa *Alexa) runPostResponseHooks(ctx context.Context, requestEnv *RequestEnvelope, responseEnv *ResponseEnvelope) {
+	if len(alexa.PostResponseHooks) == 0 {
+		return
+	}
+
+	if alexa.SensitiveSlots != nil {
+		mask := alexa.SensitiveSlots.masker(requestIntent(requestEnv))
+		responseEnv = mask.response(responseEnv)
+		requestEnv = mask.request(requestEnv)
+	}
+
+	timeout := alexa.PostResponseHookTimeout
+	if timeout <= 0 {
+		timeout = defaultPostResponseHookTimeout
+	}
+	hookDeadline := time.Now().Add(timeout)
+	if deadline, ok := ctx.Deadline(); ok && deadline.Add(-postResponseHookMargin).Before(hookDeadline) {
+		hookDeadline = deadline.Add(-postResponseHookMargin)
+	}
+	ctx, cancel := context.WithDeadline(ctx, hookDeadline)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for _, hook := range alexa.PostResponseHooks {
+		wg.Add(1)
+		go func(hook PostResponseHook) {
+			defer wg.Done()
+			if err := callPostResponseHook(ctx, hook, requestEnv, responseEnv); err != nil {
+				log.Println("Error running post response hook.", err.Error())
+			}
+		}(hook)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		log.Println("Post response hooks did not complete in time.", ctx.Err().Error())
+	}
+}
+
+func callPostResponseHook(ctx context.Context, hook PostResponseHook, requestEnv *RequestEnvelope, responseEnv *ResponseEnvelope) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return hook(ctx, requestEnv, responseEnv)
+}
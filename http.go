@@ -0,0 +1,105 @@
+package alexa
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+// maxRequestBytes limits the size of a request body. Alexa requests are well
+// below it.
+const maxRequestBytes = 1 << 20
+
+// ServeHTTP handles a request posted by Alexa to a web service endpoint. It is
+// the same as Handler with no middleware.
+//
+// The response is written and flushed before any PostResponseHooks run, so the
+// hooks do not delay Alexa. They run bounded by PostResponseHookTimeout.
+//
+// ServeHTTP does not verify the request signature or certificate chain
+// required of web service endpoints. It must be deployed behind something
+// that does.
+func (alexa *Alexa) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	alexa.Handler().ServeHTTP(w, r)
+}
+
+// Handler returns an http.Handler for the skill wrapped in middleware, the
+// first being the outermost. The RequestEnvelope is decoded before the
+// middleware runs and is available from RequestEnvelopeFromContext, and the
+// body can still be read, for example to verify its signature.
+//
+// The handler ignores the request path, so it can be mounted under any
+// prefix. Request bodies over 1 MB are rejected.
+func (alexa *Alexa) Handler(middleware ...func(http.Handler) http.Handler) http.Handler {
+	var h http.Handler = http.HandlerFunc(alexa.serveRequest)
+	for i := len(middleware) - 1; i >= 0; i-- {
+		h = middleware[i](h)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestBytes))
+		if err != nil {
+			log.Println("Error reading request.", err.Error())
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+				return
+			}
+			http.Error(w, "unable to read request", http.StatusBadRequest)
+			return
+		}
+		requestEnv := &RequestEnvelope{}
+		if err := json.Unmarshal(body, requestEnv); err != nil {
+			log.Println("Error decoding request.", err.Error())
+			http.Error(w, "unable to decode request", http.StatusBadRequest)
+			return
+		}
+
+		r = r.WithContext(WithRequestEnvelope(r.Context(), requestEnv))
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		h.ServeHTTP(w, r)
+	})
+}
+
+// serveRequest processes the RequestEnvelope in the request context.
+func (alexa *Alexa) serveRequest(w http.ResponseWriter, r *http.Request) {
+	requestEnv := RequestEnvelopeFromContext(r.Context())
+	if requestEnv == nil {
+		http.Error(w, "unable to decode request", http.StatusBadRequest)
+		return
+	}
+
+	ctx, responseEnv, err := alexa.processRequest(r.Context(), requestEnv)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	b, err := json.Marshal(responseEnv)
+	if err != nil {
+		log.Println("Error encoding response.", err.Error())
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json;charset=UTF-8")
+	w.Header().Set("Content-Length", strconv.Itoa(len(b)))
+	w.Write(b)
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+
+	// The request context is canceled once Alexa closes the connection, which
+	// must not cut the hooks short.
+	alexa.runPostResponseHooks(context.WithoutCancel(ctx), requestEnv, responseEnv)
+}
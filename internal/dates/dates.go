@@ -0,0 +1,13 @@
+// Package dates has calendar helpers shared by the packages of the SDK.
+package dates
+
+import "time"
+
+// DaysBetween counts the calendar days from the date of start to the date of
+// end, ignoring their time zones.
+func DaysBetween(start, end time.Time) int {
+	s := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	e := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.UTC)
+	// Unix seconds, as Sub saturates for dates centuries apart.
+	return int((e.Unix() - s.Unix()) / (24 * 60 * 60))
+}
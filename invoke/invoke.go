@@ -0,0 +1,275 @@
+// Package invoke runs Alexa request JSON fixtures through a skill, either
+// in-process or by posting them to a local HTTP endpoint, and prints the
+// response in a readable form. It backs the alexa-invoke command.
+//
+// To invoke a skill in-process, build a small command that registers it:
+//
+//	func main() {
+//		invoke.Register("helloworld", &alexa.Alexa{ApplicationID: "amzn1.ask.skill.<SKILL_ID>", RequestHandler: &HelloWorld{}})
+//		invoke.Main()
+//	}
+package invoke
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	alexa "github.com/ericdaugherty/alexa-skills-kit-golang"
+)
+
+// timeout matches the time Alexa waits for a skill to respond.
+const timeout = 8 * time.Second
+
+// ErrInteractionModel reports that the fixture is an interaction model rather
+// than a request.
+var ErrInteractionModel = errors.New("invoke: file is an interaction model, not a request")
+
+var (
+	mu     sync.Mutex
+	skills = map[string]*alexa.Alexa{}
+)
+
+// Register makes a skill available to be invoked in-process by name.
+func Register(name string, a *alexa.Alexa) {
+	mu.Lock()
+	defer mu.Unlock()
+	skills[name] = a
+}
+
+// Main runs the command with the process arguments and exits.
+func Main() {
+	os.Exit(Run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// Run runs the command with args, writing the response to stdout, and returns
+// the exit code. Values of the SensitiveSlots of an in-process skill are
+// masked in the output.
+func Run(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("alexa-invoke", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	skillName := flags.String("skill", "", "name of the registered skill to invoke in-process")
+	url := flags.String("url", "", "post the request to this HTTP endpoint instead of invoking in-process")
+	appID := flags.String("app-id", "", "replace the application ID in the request")
+	fakeAppID := flags.Bool("fake-app-id", false, "replace the application ID in the request with the one of the registered skill")
+	keepTimestamp := flags.Bool("keep-timestamp", false, "do not refresh the request timestamp")
+	raw := flags.Bool("raw", false, "print only the response JSON")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: alexa-invoke [flags] request.json")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+	if *fakeAppID && *url != "" {
+		fmt.Fprintln(stderr, "-fake-app-id needs a skill invoked in-process; use -app-id with -url")
+		flags.Usage()
+		return 2
+	}
+
+	requestEnv, err := ReadRequest(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	if !*keepTimestamp {
+		requestEnv.Request.Timestamp = time.Now().UTC().Format(time.RFC3339)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var responseEnv *alexa.ResponseEnvelope
+	if *url != "" {
+		setApplicationID(requestEnv, *appID)
+		responseEnv, err = Post(ctx, *url, requestEnv)
+	} else {
+		var a *alexa.Alexa
+		a, err = lookup(*skillName)
+		if err == nil {
+			if *fakeAppID {
+				setApplicationID(requestEnv, a.ApplicationID)
+			}
+			setApplicationID(requestEnv, *appID)
+			responseEnv, err = a.ProcessRequest(ctx, requestEnv)
+			responseEnv = a.SensitiveSlots.MaskResponse(requestEnv, responseEnv)
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	if err := Print(stdout, responseEnv, *raw); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// ReadRequest reads a request envelope from a JSON file.
+func ReadRequest(path string) (*alexa.RequestEnvelope, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var probe struct {
+		InteractionModel json.RawMessage `json:"interactionModel"`
+	}
+	if json.Unmarshal(b, &probe) == nil && probe.InteractionModel != nil {
+		return nil, ErrInteractionModel
+	}
+
+	requestEnv := &alexa.RequestEnvelope{}
+	if err := json.Unmarshal(b, requestEnv); err != nil {
+		return nil, fmt.Errorf("invoke: unable to decode request: %w", err)
+	}
+	if requestEnv.Request == nil {
+		return nil, errors.New("invoke: file does not contain a request")
+	}
+	if requestEnv.Session == nil {
+		requestEnv.Session = &alexa.Session{}
+	}
+	if requestEnv.Context == nil {
+		requestEnv.Context = &alexa.Context{}
+	}
+	return requestEnv, nil
+}
+
+// Post sends requestEnv to a skill served over HTTP and returns its response.
+func Post(ctx context.Context, url string, requestEnv *alexa.RequestEnvelope) (*alexa.ResponseEnvelope, error) {
+	b, err := json.Marshal(requestEnv)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("invoke: %s returned %s: %s", url, resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	responseEnv := &alexa.ResponseEnvelope{}
+	if err := json.NewDecoder(resp.Body).Decode(responseEnv); err != nil {
+		return nil, fmt.Errorf("invoke: unable to decode response: %w", err)
+	}
+	return responseEnv, nil
+}
+
+// Print writes the formatted response JSON, followed by the speech, reprompt
+// and card as readable text unless raw is set.
+func Print(w io.Writer, responseEnv *alexa.ResponseEnvelope, raw bool) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(responseEnv); err != nil {
+		return err
+	}
+	if raw || responseEnv.Response == nil {
+		return nil
+	}
+
+	response := responseEnv.Response
+	fmt.Fprintln(w)
+	if response.OutputSpeech != nil {
+		fmt.Fprintln(w, "Speech:   ", speechText(response.OutputSpeech))
+	}
+	if response.Reprompt != nil && response.Reprompt.OutputSpeech != nil {
+		fmt.Fprintln(w, "Reprompt: ", speechText(response.Reprompt.OutputSpeech))
+	}
+	if response.Card != nil {
+		fmt.Fprintln(w, "Card:     ", strings.TrimSpace(response.Card.Type+" "+response.Card.Title))
+	}
+	fmt.Fprintln(w, "End session:", response.ShouldSessionEnd)
+	return nil
+}
+
+var (
+	breakPattern = regexp.MustCompile(`<break\b[^>]*>`)
+	timePattern  = regexp.MustCompile(`time="([^"]*)"`)
+	audioPattern = regexp.MustCompile(`<audio[^>]*src="([^"]*)"[^>]*/?>`)
+	tagPattern   = regexp.MustCompile(`<[^>]*>`)
+	entities     = strings.NewReplacer("&amp;", "&", "&lt;", "<", "&gt;", ">", "&quot;", `"`, "&apos;", "'")
+)
+
+// RenderSSML renders SSML as readable text. Breaks and audio clips are shown
+// in brackets and all other markup is removed.
+func RenderSSML(ssml string) string {
+	s := breakPattern.ReplaceAllStringFunc(ssml, func(tag string) string {
+		if m := timePattern.FindStringSubmatch(tag); m != nil {
+			return " [pause " + m[1] + "] "
+		}
+		return " [pause] "
+	})
+	s = audioPattern.ReplaceAllString(s, " [audio $1] ")
+	s = tagPattern.ReplaceAllString(s, " ")
+	s = entities.Replace(s)
+	s = strings.Join(strings.Fields(s), " ")
+	return strings.NewReplacer(" .", ".", " ,", ",", " ?", "?", " !", "!").Replace(s)
+}
+
+func speechText(speech *alexa.OutputSpeech) string {
+	if speech.Type == "SSML" {
+		return RenderSSML(speech.SSML)
+	}
+	return speech.Text
+}
+
+func lookup(name string) (*alexa.Alexa, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if name != "" {
+		a, ok := skills[name]
+		if !ok {
+			return nil, fmt.Errorf("invoke: no skill registered as %q", name)
+		}
+		return a, nil
+	}
+	switch len(skills) {
+	case 0:
+		return nil, errors.New("invoke: no skill is registered, use -url to invoke a skill over HTTP")
+	case 1:
+		for _, a := range skills {
+			return a, nil
+		}
+	}
+	names := make([]string, 0, len(skills))
+	for n := range skills {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return nil, fmt.Errorf("invoke: several skills are registered, use -skill to pick one of %s", strings.Join(names, ", "))
+}
+
+func setApplicationID(requestEnv *alexa.RequestEnvelope, appID string) {
+	if appID == "" {
+		return
+	}
+	requestEnv.Session.Application.ApplicationID = appID
+	requestEnv.Context.System.Application.ApplicationID = appID
+}
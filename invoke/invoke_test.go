@@ -0,0 +1,108 @@
+package invoke
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	alexa "github.com/ericdaugherty/alexa-skills-kit-golang"
+)
+
+const launchFixture = "../samples/helloworld/launch_request.json"
+
+type launchHandler struct{}
+
+func (h *launchHandler) OnSessionStarted(context.Context, *alexa.Request, *alexa.Session, *alexa.Context, *alexa.Response) error {
+	return nil
+}
+
+func (h *launchHandler) OnLaunch(ctx context.Context, request *alexa.Request, session *alexa.Session, aContext *alexa.Context, response *alexa.Response) error {
+	response.SetOutputSSML(`<speak>Welcome.<break time="500ms"/>Say <say-as interpret-as="characters">hi</say-as> &amp; more.</speak>`)
+	response.SetRepromptText("Say hello")
+	response.ShouldSessionEnd = false
+	return nil
+}
+
+func (h *launchHandler) OnIntent(context.Context, *alexa.Request, *alexa.Session, *alexa.Context, *alexa.Response) error {
+	return errors.New("invalid intent")
+}
+
+func (h *launchHandler) OnSessionEnded(context.Context, *alexa.Request, *alexa.Session, *alexa.Context, *alexa.Response) error {
+	return nil
+}
+
+func TestRunInProcess(t *testing.T) {
+	Register("test", &alexa.Alexa{ApplicationID: "amzn1.ask.skill.ABC123", RequestHandler: &launchHandler{}})
+
+	var stdout, stderr bytes.Buffer
+	code := Run([]string{"-skill", "test", launchFixture}, &stdout, &stderr)
+	if code == 0 {
+		t.Error("Expected mismatched application ID to fail but it succeeded.")
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	code = Run([]string{"-skill", "test", "-fake-app-id", launchFixture}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("Expected exit code 0 but was %d: %s", code, stderr.String())
+	}
+	out := stdout.String()
+	if !strings.Contains(out, "Speech:    Welcome. [pause 500ms] Say hi & more.") {
+		t.Error("Expected SSML to be rendered as readable text but output was", out)
+	}
+	if !strings.Contains(out, "Reprompt:  Say hello") {
+		t.Error("Expected reprompt in output but output was", out)
+	}
+}
+
+func TestRunOverHTTP(t *testing.T) {
+	server := httptest.NewServer(&alexa.Alexa{ApplicationID: "amzn1.ask.skill.ABC123", RequestHandler: &launchHandler{}})
+	defer server.Close()
+
+	var stdout, stderr bytes.Buffer
+	code := Run([]string{"-url", server.URL, "-app-id", "amzn1.ask.skill.ABC123", "-raw", launchFixture}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("Expected exit code 0 but was %d: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), `"shouldEndSession": false`) {
+		t.Error("Expected response JSON in output but output was", stdout.String())
+	}
+	if strings.Contains(stdout.String(), "Speech:") {
+		t.Error("Expected -raw to print only JSON but output was", stdout.String())
+	}
+}
+
+func TestRunFakeAppIDOverHTTP(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := Run([]string{"-url", "http://localhost", "-fake-app-id", launchFixture}, &stdout, &stderr)
+	if code != 2 {
+		t.Errorf("Expected exit code 2 but was %d", code)
+	}
+	if !strings.Contains(stderr.String(), "-fake-app-id") {
+		t.Error("Expected a usage error but output was", stderr.String())
+	}
+}
+
+func TestReadRequestRejectsInteractionModel(t *testing.T) {
+	_, err := ReadRequest("../samples/helloworld/interaction.json")
+	if !errors.Is(err, ErrInteractionModel) {
+		t.Error("Expected ErrInteractionModel but got", err)
+	}
+}
+
+func TestRenderSSML(t *testing.T) {
+	tests := map[string]string{
+		"<speak>Hello world</speak>":                                   "Hello world",
+		`<speak>One<break/>two</speak>`:                                "One [pause] two",
+		`<speak><audio src="https://example.com/a.mp3"/> done</speak>`: "[audio https://example.com/a.mp3] done",
+		`<speak><p>Hi</p><p>there &lt;you&gt;</p></speak>`:             "Hi there <you>",
+	}
+	for in, exp := range tests {
+		if out := RenderSSML(in); out != exp {
+			t.Errorf("Expected %s to render as %q but was %q", in, exp, out)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+// Package jsonschema generates JSON Schemas from Go types, following the
+// rules of encoding/json, and validates JSON documents against them.
+//
+// Generated schemas are strict: objects may only have the fields of their Go
+// type, so a document that decodes without error but loses data does not
+// validate. SDK returns the schemas of the request, response and directive
+// types of the SDK.
+package jsonschema
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Draft is the JSON Schema dialect of generated schemas.
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Types is the type keyword of a Schema. It is written as a string when it
+// has one type.
+type Types []string
+
+// MarshalJSON writes a single type as a string.
+func (t Types) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+// Schema is the subset of JSON Schema used for Go types. A Schema with no
+// keywords accepts any value.
+type Schema struct {
+	Schema     string             `json:"$schema,omitempty"`
+	Ref        string             `json:"$ref,omitempty"`
+	Title      string             `json:"title,omitempty"`
+	Type       Types              `json:"type,omitempty"`
+	Format     string             `json:"format,omitempty"`
+	Properties map[string]*Schema `json:"properties,omitempty"`
+	// AdditionalProperties is the schema of fields not in Properties. If it
+	// is nil and Closed is set, no other fields are allowed.
+	AdditionalProperties *Schema            `json:"-"`
+	Closed               bool               `json:"-"`
+	Items                *Schema            `json:"items,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`
+}
+
+// MarshalJSON writes AdditionalProperties, or false if Closed.
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	type schema Schema
+	out := struct {
+		*schema
+		AdditionalProperties interface{} `json:"additionalProperties,omitempty"`
+	}{schema: (*schema)(s)}
+	if s.AdditionalProperties != nil {
+		out.AdditionalProperties = s.AdditionalProperties
+	} else if s.Closed {
+		out.AdditionalProperties = false
+	}
+	return json.Marshal(out)
+}
+
+// Generate returns the schema of the type of v. Named struct types are
+// defined once, in Defs, and referenced by name.
+func Generate(v interface{}) *Schema {
+	g := newGenerator()
+	return g.root(g.schema(reflect.TypeOf(v)))
+}
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	rawType       = reflect.TypeOf(json.RawMessage{})
+	marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textType      = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+type generator struct {
+	defs  map[string]*Schema
+	names map[reflect.Type]string
+}
+
+func newGenerator() *generator {
+	return &generator{defs: make(map[string]*Schema), names: make(map[reflect.Type]string)}
+}
+
+// root returns s as a document, with the definitions generated so far.
+func (g *generator) root(s *Schema) *Schema {
+	root := *s
+	root.Schema = Draft
+	root.Defs = g.defs
+	return &root
+}
+
+// schema returns the schema of t.
+func (g *generator) schema(t reflect.Type) *Schema {
+	if t == nil {
+		return &Schema{}
+	}
+	switch {
+	case t == timeType:
+		return &Schema{Type: Types{"string"}, Format: "date-time"}
+	case t == rawType:
+		return &Schema{}
+	case t.Implements(marshalerType) || reflect.PointerTo(t).Implements(marshalerType):
+		return &Schema{}
+	case t.Implements(textType) || reflect.PointerTo(t).Implements(textType):
+		return &Schema{Type: Types{"string"}}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return nullable(g.schema(t.Elem()))
+	case reflect.Interface:
+		return &Schema{}
+	case reflect.Bool:
+		return &Schema{Type: Types{"boolean"}}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: Types{"integer"}}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: Types{"number"}}
+	case reflect.String:
+		return &Schema{Type: Types{"string"}}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: Types{"string", "null"}}
+		}
+		return &Schema{Type: Types{"array", "null"}, Items: g.schema(t.Elem())}
+	case reflect.Array:
+		return &Schema{Type: Types{"array"}, Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: Types{"object", "null"}, AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t)
+		}
+		return g.ref(t)
+	}
+	// Channels and functions cannot be encoded.
+	return &Schema{Type: Types{"null"}}
+}
+
+// ref defines the named struct type t, and returns a reference to it.
+func (g *generator) ref(t reflect.Type) *Schema {
+	name, ok := g.names[t]
+	if !ok {
+		name = t.Name()
+		if _, taken := g.defs[name]; taken {
+			pkg := t.PkgPath()
+			name = pkg[strings.LastIndex(pkg, "/")+1:] + "." + name
+		}
+		g.names[t] = name
+		g.defs[name] = &Schema{}
+		*g.defs[name] = *g.object(t)
+	}
+	return &Schema{Ref: "#/$defs/" + name}
+}
+
+// object returns the schema of the fields of struct type t.
+func (g *generator) object(t reflect.Type) *Schema {
+	s := &Schema{Type: Types{"object"}, Properties: make(map[string]*Schema), Closed: true}
+	g.fields(s, t)
+	return s
+}
+
+// fields adds the fields of struct type t to s. Embedded structs are added
+// last, as fields of the outer struct hide theirs.
+func (g *generator) fields(s *Schema, t reflect.Type) {
+	var embedded []reflect.Type
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				embedded = append(embedded, ft)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		if strings.Contains(","+opts+",", ",string,") {
+			s.Properties[name] = &Schema{Type: Types{"string"}}
+			continue
+		}
+		s.Properties[name] = g.schema(f.Type)
+	}
+
+	for _, e := range embedded {
+		inner := &Schema{Properties: make(map[string]*Schema)}
+		g.fields(inner, e)
+		for name, p := range inner.Properties {
+			if _, ok := s.Properties[name]; !ok {
+				s.Properties[name] = p
+			}
+		}
+	}
+}
+
+// nullable returns s, also accepting null.
+func nullable(s *Schema) *Schema {
+	if s.Ref != "" {
+		return &Schema{AnyOf: []*Schema{s, {Type: Types{"null"}}}}
+	}
+	if len(s.Type) > 0 && !hasType(s.Type, "null") {
+		s.Type = append(s.Type, "null")
+	}
+	return s
+}
+
+func hasType(types Types, t string) bool {
+	for _, x := range types {
+		if x == t {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	alexa "github.com/ericdaugherty/alexa-skills-kit-golang"
+)
+
+type base struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type node struct {
+	base
+	Name     string            `json:"title"`
+	Count    int               `json:"count,string"`
+	Weight   float64           `json:"weight"`
+	Created  time.Time         `json:"created"`
+	Parent   *node             `json:"parent,omitempty"`
+	Children []node            `json:"children"`
+	Labels   map[string]string `json:"labels"`
+	Extra    interface{}       `json:"extra"`
+	Skipped  string            `json:"-"`
+	internal string
+}
+
+func problems(err error) []string {
+	var v *ValidationError
+	if errors.As(err, &v) {
+		return v.Problems
+	}
+	return nil
+}
+
+func TestGenerate(t *testing.T) {
+	s := Generate(node{})
+	def := s.Defs["node"]
+	if s.Ref != "#/$defs/node" || def == nil {
+		t.Fatal("Expected a reference to the node definition but was", s.Ref)
+	}
+	var names []string
+	for name := range def.Properties {
+		names = append(names, name)
+	}
+	if len(names) != 10 {
+		t.Error("Expected 10 properties but were", names)
+	}
+	if def.Properties["count"].Type[0] != "string" || def.Properties["created"].Format != "date-time" {
+		t.Error("Expected string options and times to be strings")
+	}
+
+	b, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal("Error marshaling schema. " + err.Error())
+	}
+	if !strings.Contains(string(b), `"additionalProperties":false`) || !strings.Contains(string(b), `"$schema":"`+Draft+`"`) {
+		t.Error("Expected a closed 2020-12 schema but was", string(b))
+	}
+}
+
+func TestValidate(t *testing.T) {
+	s := Generate(node{})
+	valid := `{"id":"1","name":"n","title":"t","count":"3","weight":1,"created":"2026-03-01T09:00:00Z",
+		"parent":{"id":"0"},"children":[{"id":"2"}],"labels":{"a":"b"},"extra":[1,"x"]}`
+	if err := s.Validate([]byte(valid)); err != nil {
+		t.Error("Expected a valid document but got", err)
+	}
+
+	invalid := `{"id":1,"weight":"heavy","parent":{"colour":"red"},"children":[{"count":3}],"labels":{"a":1}}`
+	got := problems(s.Validate([]byte(invalid)))
+	expected := []string{
+		"/children/0/count: expected string but was integer",
+		"/id: expected string but was integer",
+		"/labels/a: expected string but was integer",
+		"/parent/colour: unknown field",
+		"/weight: expected number but was string",
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Error("Expected problems", expected, "but were", got)
+	}
+}
+
+func TestSDKResponse(t *testing.T) {
+	responseEnv := &alexa.ResponseEnvelope{Version: "1.0", Response: &alexa.Response{}}
+	responseEnv.Response.SetOutputText("Hello")
+	responseEnv.Response.AddDialogDirective("Dialog.ElicitSlot", "Size", "", &alexa.Intent{Name: "OrderIntent"})
+	responseEnv.Response.AddConnectionsStartConnection("connection://AMAZON.VerifyPerson/2", map[string]int{"level": 400}, "t")
+	b, _ := json.Marshal(responseEnv)
+
+	s := SDK()["ResponseEnvelope"]
+	if err := s.Validate(b); err != nil {
+		t.Error("Expected an SDK response to be valid but got", err)
+	}
+
+	bad := strings.Replace(string(b), `"type":"Dialog.ElicitSlot"`, `"type":"Dialog.ElicitSlot","slot":"Size"`, 1)
+	got := problems(s.Validate([]byte(bad)))
+	if len(got) != 1 || !strings.HasPrefix(got[0], "/response/directives/0: does not match any of") {
+		t.Error("Expected an invalid directive but problems were", got)
+	}
+}
+
+func TestSDKRequest(t *testing.T) {
+	request := `{"version":"1.0","session":{"new":true,"sessionId":"s","application":{"applicationId":"a"},
+		"user":{"userId":"u"},"attributes":{"string":{}}},
+		"request":{"type":"IntentRequest","requestId":"r","locale":"en-US","timestamp":"2026-03-01T09:00:00Z",
+		"intent":{"name":"RecipeIntent","slots":{"Item":{"name":"Item","value":"snowball"}}}}}`
+	s := SDK()["RequestEnvelope"]
+	if err := s.Validate([]byte(request)); err != nil {
+		t.Error("Expected a valid request but got", err)
+	}
+
+	drifted := strings.Replace(request, `"value":"snowball"`, `"values":["snowball"]`, 1)
+	if got := problems(s.Validate([]byte(drifted))); len(got) != 1 || got[0] != "/request/intent/slots/Item/values: unknown field" {
+		t.Error("Expected an unknown slot field but problems were", got)
+	}
+}
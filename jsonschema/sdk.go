@@ -0,0 +1,50 @@
+package jsonschema
+
+import (
+	"reflect"
+
+	alexa "github.com/ericdaugherty/alexa-skills-kit-golang"
+	"github.com/ericdaugherty/alexa-skills-kit-golang/music"
+	"github.com/ericdaugherty/alexa-skills-kit-golang/video"
+)
+
+// Directives are the directive types of the SDK. The directives of a
+// ResponseEnvelope must match one of them.
+var Directives = []interface{}{
+	alexa.AudioPlayerDirective{},
+	alexa.DialogDirective{},
+	alexa.ConnectionsDirective{},
+}
+
+// SDK returns the schemas of the request, response and directive types of
+// the SDK, by name, such as RequestEnvelope or video.DirectiveEnvelope.
+func SDK() map[string]*Schema {
+	schemas := map[string]*Schema{
+		"RequestEnvelope":         Generate(alexa.RequestEnvelope{}),
+		"ResponseEnvelope":        responseSchema(),
+		"video.DirectiveEnvelope": Generate(video.DirectiveEnvelope{}),
+		"video.EventEnvelope":     Generate(video.EventEnvelope{}),
+		"music.Request":           Generate(music.Request{}),
+		"music.Response":          Generate(music.Response{}),
+	}
+	for _, d := range Directives {
+		schemas[reflect.TypeOf(d).Name()] = Generate(d)
+	}
+	for name, s := range schemas {
+		s.Title = name
+	}
+	return schemas
+}
+
+// responseSchema returns the schema of alexa.ResponseEnvelope, with
+// directives matching one of Directives.
+func responseSchema() *Schema {
+	g := newGenerator()
+	s := g.schema(reflect.TypeOf(alexa.ResponseEnvelope{}))
+	directives := &Schema{}
+	for _, d := range Directives {
+		directives.AnyOf = append(directives.AnyOf, g.schema(reflect.TypeOf(d)))
+	}
+	g.defs["Response"].Properties["directives"].Items = directives
+	return g.root(s)
+}
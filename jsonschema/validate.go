@@ -0,0 +1,177 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidDocument is matched by errors.Is for a *ValidationError.
+var ErrInvalidDocument = errors.New("document does not match schema")
+
+// ValidationError lists every problem found in a document. Each problem
+// starts with the JSON Pointer of the value, such as /request/intent.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return ErrInvalidDocument.Error() + ": " + strings.Join(e.Problems, "; ")
+}
+
+// Unwrap returns ErrInvalidDocument.
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidDocument
+}
+
+// Validate checks the JSON document data against s. Problems are returned as
+// a *ValidationError.
+func (s *Schema) Validate(data []byte) error {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return err
+	}
+	if d.More() {
+		return errors.New("jsonschema: more than one JSON value")
+	}
+
+	var problems []string
+	s.validate(s, v, "", &problems)
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// ValidateFile checks the JSON document in the file at path against s.
+func (s *Schema) ValidateFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := s.Validate(data); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+func (s *Schema) validate(root *Schema, v interface{}, path string, problems *[]string) {
+	if s.Ref != "" {
+		def, ok := root.Defs[strings.TrimPrefix(s.Ref, "#/$defs/")]
+		if !ok {
+			*problems = append(*problems, pointer(path)+": unresolved reference "+s.Ref)
+			return
+		}
+		def.validate(root, v, path, problems)
+		return
+	}
+
+	if len(s.AnyOf) > 0 {
+		s.validateAnyOf(root, v, path, problems)
+		return
+	}
+
+	if len(s.Type) > 0 {
+		t := jsonType(v)
+		if !hasType(s.Type, t) && !(t == "integer" && hasType(s.Type, "number")) {
+			*problems = append(*problems, fmt.Sprintf("%s: expected %s but was %s", pointer(path), strings.Join(s.Type, " or "), t))
+			return
+		}
+	}
+
+	switch v := v.(type) {
+	case map[string]interface{}:
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			p := path + "/" + escape(name)
+			if f, ok := s.Properties[name]; ok {
+				f.validate(root, v[name], p, problems)
+			} else if s.AdditionalProperties != nil {
+				s.AdditionalProperties.validate(root, v[name], p, problems)
+			} else if s.Closed {
+				*problems = append(*problems, pointer(p)+": unknown field")
+			}
+		}
+	case []interface{}:
+		if s.Items != nil {
+			for i, item := range v {
+				s.Items.validate(root, item, path+"/"+strconv.Itoa(i), problems)
+			}
+		}
+	}
+}
+
+// validateAnyOf accepts v if a subschema does. Null and a single other
+// subschema, as for Go pointers, report the problems of that subschema.
+func (s *Schema) validateAnyOf(root *Schema, v interface{}, path string, problems *[]string) {
+	var others []*Schema
+	for _, sub := range s.AnyOf {
+		if hasType(sub.Type, "null") && len(sub.Type) == 1 && sub.Ref == "" {
+			if v == nil {
+				return
+			}
+			continue
+		}
+		others = append(others, sub)
+	}
+	if len(others) == 1 {
+		others[0].validate(root, v, path, problems)
+		return
+	}
+
+	var names []string
+	for _, sub := range others {
+		var p []string
+		sub.validate(root, v, path, &p)
+		if len(p) == 0 {
+			return
+		}
+		names = append(names, strings.TrimPrefix(sub.Ref, "#/$defs/"))
+	}
+	*problems = append(*problems, fmt.Sprintf("%s: does not match any of %s", pointer(path), strings.Join(names, ", ")))
+}
+
+// jsonType returns the JSON Schema type of a decoded value.
+func jsonType(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func pointer(path string) string {
+	if path == "" {
+		return "/"
+	}
+	return path
+}
+
+// escape escapes name for use in a JSON Pointer.
+func escape(name string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(name)
+}
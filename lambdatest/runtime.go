@@ -0,0 +1,331 @@
+// Package lambdatest emulates the AWS Lambda Runtime API locally, so a skill
+// can be tested end to end as deployed: either the compiled handler binary,
+// started with Start, or an in-process lambda.Start target, started with
+// StartFunc. Events are pushed with Invoke or InvokeAlexa, and responses,
+// function errors and timeouts are returned as they would be by Lambda.
+package lambdatest
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	alexa "github.com/ericdaugherty/alexa-skills-kit-golang"
+)
+
+// DefaultTimeout matches the time Alexa waits for a skill to respond.
+const DefaultTimeout = 8 * time.Second
+
+const apiPrefix = "/2018-06-01/runtime/"
+
+// Errors reported by Runtime.
+var (
+	ErrTimeout    = errors.New("lambdatest: task timed out")
+	ErrInitFailed = errors.New("lambdatest: function failed to initialize")
+	ErrClosed     = errors.New("lambdatest: runtime closed")
+)
+
+// FunctionError is an error returned by the handler, as reported to the
+// Runtime API.
+type FunctionError struct {
+	Message    string   `json:"errorMessage"`
+	Type       string   `json:"errorType"`
+	StackTrace []string `json:"stackTrace,omitempty"`
+}
+
+func (e *FunctionError) Error() string {
+	return "lambdatest: " + e.Type + ": " + e.Message
+}
+
+type invocation struct {
+	id       string
+	payload  []byte
+	deadline time.Time
+	done     chan result
+}
+
+type result struct {
+	payload []byte
+	err     error
+}
+
+// Runtime is an emulated Lambda Runtime API serving one function.
+type Runtime struct {
+	// Timeout is the function timeout. Default value is DefaultTimeout.
+	Timeout time.Duration
+	// FunctionName is passed to the function. Default value is "skill".
+	FunctionName string
+	// Output receives the output of a started binary. Default value is
+	// os.Stderr.
+	Output io.Writer
+
+	server  *httptest.Server
+	pending chan *invocation
+	closed  chan struct{}
+
+	mu       sync.Mutex
+	inflight map[string]*invocation
+	initErr  *FunctionError
+	cmd      *exec.Cmd
+	start    func() error
+}
+
+// NewRuntime starts an emulated Runtime API.
+func NewRuntime() *Runtime {
+	rt := &Runtime{
+		pending:  make(chan *invocation),
+		closed:   make(chan struct{}),
+		inflight: make(map[string]*invocation),
+	}
+	rt.server = httptest.NewServer(http.HandlerFunc(rt.serveHTTP))
+	return rt
+}
+
+// Addr returns the host and port of the Runtime API, the value of
+// AWS_LAMBDA_RUNTIME_API.
+func (rt *Runtime) Addr() string {
+	return strings.TrimPrefix(rt.server.URL, "http://")
+}
+
+func (rt *Runtime) functionName() string {
+	if rt.FunctionName == "" {
+		return "skill"
+	}
+	return rt.FunctionName
+}
+
+// Env returns the environment Lambda sets for the function.
+func (rt *Runtime) Env() []string {
+	return []string{
+		"AWS_LAMBDA_RUNTIME_API=" + rt.Addr(),
+		"AWS_LAMBDA_FUNCTION_NAME=" + rt.functionName(),
+		"AWS_LAMBDA_FUNCTION_VERSION=$LATEST",
+		"AWS_LAMBDA_FUNCTION_MEMORY_SIZE=128",
+		"AWS_REGION=us-east-1",
+		"_HANDLER=bootstrap",
+	}
+}
+
+// Start runs the handler binary at path against the Runtime. Like Lambda, the
+// process is restarted after a timeout.
+func (rt *Runtime) Start(path string, args ...string) error {
+	output := rt.Output
+	if output == nil {
+		output = os.Stderr
+	}
+	start := func() error {
+		cmd := exec.Command(path, args...)
+		cmd.Env = append(os.Environ(), rt.Env()...)
+		cmd.Stdout = output
+		cmd.Stderr = output
+		if err := cmd.Start(); err != nil {
+			return err
+		}
+		rt.mu.Lock()
+		rt.cmd = cmd
+		rt.mu.Unlock()
+		go cmd.Wait()
+		return nil
+	}
+	rt.mu.Lock()
+	rt.start = start
+	rt.mu.Unlock()
+	return start()
+}
+
+// StartFunc runs f, which calls lambda.Start, in a new goroutine against the
+// Runtime. The environment is set as by Lambda with os.Setenv, for the whole
+// process, so only one Runtime can serve an in-process function at a time, and
+// tests using it cannot run in parallel. A function cannot be restarted: after
+// a timeout, its late response is dropped and it serves the next invocation
+// once its handler returns. A handler that ignores its context times out every
+// later invocation; use Start to test it.
+func (rt *Runtime) StartFunc(f func()) {
+	for _, kv := range rt.Env() {
+		k, v, _ := strings.Cut(kv, "=")
+		os.Setenv(k, v)
+	}
+	go f()
+}
+
+// Invoke sends payload to the function and returns its response. A handler
+// error is returned as a *FunctionError, and a response later than Timeout as
+// ErrTimeout.
+func (rt *Runtime) Invoke(ctx context.Context, payload []byte) ([]byte, error) {
+	timeout := rt.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	inv := &invocation{
+		id:       newRequestID(),
+		payload:  payload,
+		deadline: time.Now().Add(timeout),
+		done:     make(chan result, 1),
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	rt.mu.Lock()
+	initErr := rt.initErr
+	rt.inflight[inv.id] = inv
+	rt.mu.Unlock()
+	defer rt.forget(inv.id)
+	if initErr != nil {
+		return nil, errors.Join(ErrInitFailed, initErr)
+	}
+
+	select {
+	case rt.pending <- inv:
+	case <-timer.C:
+		return nil, ErrTimeout
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-rt.closed:
+		return nil, ErrClosed
+	}
+
+	select {
+	case r := <-inv.done:
+		return r.payload, r.err
+	case <-timer.C:
+		rt.restart()
+		return nil, ErrTimeout
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-rt.closed:
+		return nil, ErrClosed
+	}
+}
+
+// InvokeAlexa sends requestEnv to the function and decodes its response.
+func (rt *Runtime) InvokeAlexa(ctx context.Context, requestEnv *alexa.RequestEnvelope) (*alexa.ResponseEnvelope, error) {
+	payload, err := json.Marshal(requestEnv)
+	if err != nil {
+		return nil, err
+	}
+	b, err := rt.Invoke(ctx, payload)
+	if err != nil {
+		return nil, err
+	}
+	responseEnv := &alexa.ResponseEnvelope{}
+	if err := json.Unmarshal(b, responseEnv); err != nil {
+		return nil, err
+	}
+	return responseEnv, nil
+}
+
+// Close stops a started binary and the Runtime API. An in-process function
+// cannot be stopped, and lambda.Start exits the process when the Runtime API
+// fails, so its connections are left open, with its next poll never answered.
+func (rt *Runtime) Close() {
+	close(rt.closed)
+	rt.mu.Lock()
+	if rt.cmd != nil {
+		rt.cmd.Process.Kill()
+	}
+	rt.start = nil
+	rt.mu.Unlock()
+	rt.server.Listener.Close()
+}
+
+func (rt *Runtime) forget(id string) {
+	rt.mu.Lock()
+	delete(rt.inflight, id)
+	rt.mu.Unlock()
+}
+
+// restart replaces a started binary after a timeout, as Lambda does.
+func (rt *Runtime) restart() {
+	rt.mu.Lock()
+	cmd, start := rt.cmd, rt.start
+	rt.mu.Unlock()
+	if cmd == nil || start == nil {
+		return
+	}
+	cmd.Process.Kill()
+	start()
+}
+
+func (rt *Runtime) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, apiPrefix)
+	switch {
+	case r.Method == http.MethodGet && path == "invocation/next":
+		rt.next(w, r)
+	case r.Method == http.MethodPost && path == "init/error":
+		rt.mu.Lock()
+		rt.initErr = readError(r)
+		rt.mu.Unlock()
+		w.WriteHeader(http.StatusAccepted)
+	case r.Method == http.MethodPost && strings.HasPrefix(path, "invocation/"):
+		id, kind, _ := strings.Cut(strings.TrimPrefix(path, "invocation/"), "/")
+		rt.mu.Lock()
+		inv := rt.inflight[id]
+		rt.mu.Unlock()
+		if inv == nil {
+			// The invocation timed out. Lambda drops late responses.
+			io.Copy(io.Discard, r.Body)
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		var res result
+		switch kind {
+		case "response":
+			res.payload, _ = io.ReadAll(r.Body)
+		case "error":
+			res.err = readError(r)
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		select {
+		case inv.done <- res:
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			http.Error(w, "response already sent", http.StatusBadRequest)
+		}
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// next long-polls for the next invocation.
+func (rt *Runtime) next(w http.ResponseWriter, r *http.Request) {
+	select {
+	case inv := <-rt.pending:
+		w.Header().Set("Lambda-Runtime-Aws-Request-Id", inv.id)
+		w.Header().Set("Lambda-Runtime-Deadline-Ms", strconv.FormatInt(inv.deadline.UnixMilli(), 10))
+		w.Header().Set("Lambda-Runtime-Invoked-Function-Arn", "arn:aws:lambda:us-east-1:000000000000:function:"+rt.functionName())
+		w.Header().Set("Lambda-Runtime-Trace-Id", "Root=1-00000000-000000000000000000000000;Sampled=0")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(inv.payload)
+	case <-r.Context().Done():
+	case <-rt.closed:
+		// Park the poll, see Close.
+		<-r.Context().Done()
+	}
+}
+
+func readError(r *http.Request) *FunctionError {
+	e := &FunctionError{}
+	if err := json.NewDecoder(r.Body).Decode(e); err != nil || e.Type == "" {
+		e.Type = r.Header.Get("Lambda-Runtime-Function-Error-Type")
+	}
+	return e
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
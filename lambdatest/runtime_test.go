@@ -0,0 +1,132 @@
+package lambdatest
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	alexa "github.com/ericdaugherty/alexa-skills-kit-golang"
+)
+
+type helloHandler struct{}
+
+func (h *helloHandler) OnSessionStarted(context.Context, *alexa.Request, *alexa.Session, *alexa.Context, *alexa.Response) error {
+	return nil
+}
+
+func (h *helloHandler) OnLaunch(ctx context.Context, request *alexa.Request, session *alexa.Session, aContext *alexa.Context, response *alexa.Response) error {
+	response.SetOutputText("Hello")
+	return nil
+}
+
+func (h *helloHandler) OnIntent(ctx context.Context, request *alexa.Request, session *alexa.Session, aContext *alexa.Context, response *alexa.Response) error {
+	switch request.Intent.Name {
+	case "SlowIntent":
+		<-ctx.Done()
+		time.Sleep(100 * time.Millisecond)
+	case "FailIntent":
+		return errors.New("Invalid Intent")
+	}
+	return nil
+}
+
+func (h *helloHandler) OnSessionEnded(context.Context, *alexa.Request, *alexa.Session, *alexa.Context, *alexa.Response) error {
+	return nil
+}
+
+func startSkill(rt *Runtime) {
+	a := &alexa.Alexa{RequestHandler: &helloHandler{}, IgnoreApplicationID: true, IgnoreTimestamp: true}
+	rt.StartFunc(func() {
+		lambda.Start(a.ProcessRequest)
+	})
+}
+
+func newRequest(requestType, intent string) *alexa.RequestEnvelope {
+	requestEnv := &alexa.RequestEnvelope{
+		Version: "1.0",
+		Session: &alexa.Session{New: false},
+		Request: &alexa.Request{Type: requestType, RequestID: "amzn1.echo-api.request.1", Locale: "en-US"},
+		Context: &alexa.Context{},
+	}
+	requestEnv.Request.Intent.Name = intent
+	return requestEnv
+}
+
+func TestInvokeAlexa(t *testing.T) {
+	rt := NewRuntime()
+	defer rt.Close()
+	startSkill(rt)
+
+	responseEnv, err := rt.InvokeAlexa(context.Background(), newRequest("LaunchRequest", ""))
+	if err != nil {
+		t.Fatal("Error invoking skill. " + err.Error())
+	}
+	if responseEnv.Response.OutputSpeech.Text != "Hello" {
+		t.Error("Expected Hello but was", responseEnv.Response.OutputSpeech)
+	}
+
+	_, err = rt.InvokeAlexa(context.Background(), newRequest("IntentRequest", "FailIntent"))
+	var fe *FunctionError
+	if !errors.As(err, &fe) || fe.Message != "Invalid Intent" {
+		t.Error("Expected the handler error to be returned but got", err)
+	}
+}
+
+func TestInvokeTimeout(t *testing.T) {
+	rt := NewRuntime()
+	rt.Timeout = 200 * time.Millisecond
+	defer rt.Close()
+	startSkill(rt)
+
+	start := time.Now()
+	if _, err := rt.InvokeAlexa(context.Background(), newRequest("IntentRequest", "SlowIntent")); err != ErrTimeout {
+		t.Error("Expected ErrTimeout but got", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Error("Expected the invocation to time out after Timeout but took", time.Since(start))
+	}
+
+	// The function recovers for the next invocation.
+	if _, err := rt.InvokeAlexa(context.Background(), newRequest("LaunchRequest", "")); err != nil {
+		t.Error("Expected the next invocation to succeed but got", err)
+	}
+}
+
+func TestStart(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds a binary")
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+	path := filepath.Join(t.TempDir(), "bootstrap")
+	if out, err := exec.Command(goBin, "build", "-o", path, "./testdata/skill").CombinedOutput(); err != nil {
+		t.Fatal("Error building skill. " + string(out))
+	}
+
+	rt := NewRuntime()
+	rt.Timeout = 500 * time.Millisecond
+	defer rt.Close()
+	if err := rt.Start(path); err != nil {
+		t.Fatal("Error starting skill. " + err.Error())
+	}
+
+	responseEnv, err := rt.InvokeAlexa(context.Background(), newRequest("LaunchRequest", ""))
+	if err != nil || responseEnv.Response.OutputSpeech.Text != "Hello from skill" {
+		t.Fatal("Expected Hello from skill but got", responseEnv, err)
+	}
+	if _, err := rt.InvokeAlexa(context.Background(), newRequest("IntentRequest", "SlowIntent")); err != ErrTimeout {
+		t.Error("Expected ErrTimeout but got", err)
+	}
+
+	// The binary ignores its deadline, so it only recovers by restarting.
+	responseEnv, err = rt.InvokeAlexa(context.Background(), newRequest("LaunchRequest", ""))
+	if err != nil || responseEnv.Response.OutputSpeech.Text != "Hello from skill" {
+		t.Error("Expected the restarted skill to respond but got", responseEnv, err)
+	}
+}
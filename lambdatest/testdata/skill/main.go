@@ -0,0 +1,25 @@
+// Command skill is a handler binary for the tests of Runtime.Start. It hangs
+// on SlowIntent without watching its deadline, so only a restart recovers it.
+package main
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	alexa "github.com/ericdaugherty/alexa-skills-kit-golang"
+)
+
+func handle(ctx context.Context, requestEnv *alexa.RequestEnvelope) (*alexa.ResponseEnvelope, error) {
+	if requestEnv.Request.Intent.Name == "SlowIntent" {
+		time.Sleep(time.Hour)
+	}
+	responseEnv := &alexa.ResponseEnvelope{Version: "1.0", Response: &alexa.Response{}}
+	responseEnv.Response.SetOutputText("Hello from " + os.Getenv("AWS_LAMBDA_FUNCTION_NAME"))
+	return responseEnv, nil
+}
+
+func main() {
+	lambda.Start(handle)
+}
@@ -0,0 +1,171 @@
+// Package lifecycle keeps track of each user's visits to a skill: the first
+// and last visit, the number of visits and the streak of consecutive days
+// with a visit. The Interceptor updates them in a PersistenceAdapter at the
+// start of every session and makes them available to handlers with
+// FromContext.
+package lifecycle
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	alexa "github.com/ericdaugherty/alexa-skills-kit-golang"
+	"github.com/ericdaugherty/alexa-skills-kit-golang/internal/dates"
+)
+
+// DefaultKey is the persistent attribute the lifecycle is stored under.
+const DefaultKey = "lifecycle"
+
+type lifecycleKey struct{}
+
+// Attributes describe the visits of a user. A visit is a new session.
+type Attributes struct {
+	FirstVisit time.Time `json:"firstVisit"`
+	LastVisit  time.Time `json:"lastVisit"`
+	// PreviousVisit is the visit before LastVisit, or zero on the first visit.
+	PreviousVisit time.Time `json:"previousVisit"`
+	Visits        int       `json:"visits"`
+	// Streak is the number of consecutive days, up to the last visit, with a
+	// visit.
+	Streak int `json:"streak"`
+}
+
+// IsFirstVisit reports whether the current session is the user's first.
+func (a *Attributes) IsFirstVisit() bool {
+	return a.Visits <= 1
+}
+
+// DaysSincePreviousVisit returns the number of calendar days between the
+// previous visit and the last, in the time zone of LastVisit, or 0 on the
+// first visit.
+func (a *Attributes) DaysSincePreviousVisit() int {
+	if a.PreviousVisit.IsZero() {
+		return 0
+	}
+	return dates.DaysBetween(a.PreviousVisit.In(a.LastVisit.Location()), a.LastVisit)
+}
+
+// lazyAttributes loads the lifecycle on first use, for requests within a
+// session that may not need it.
+type lazyAttributes struct {
+	once sync.Once
+	load func() (*Attributes, error)
+	a    *Attributes
+}
+
+// FromContext returns the lifecycle of the user of the request, or nil if the
+// Interceptor did not run or the lifecycle could not be read.
+func FromContext(ctx context.Context) *Attributes {
+	l, ok := ctx.Value(lifecycleKey{}).(*lazyAttributes)
+	if !ok {
+		return nil
+	}
+	l.once.Do(func() {
+		a, err := l.load()
+		if err != nil {
+			alexa.LoggerFromContext(ctx).Println("Error reading lifecycle.", err.Error())
+			return
+		}
+		l.a = a
+	})
+	return l.a
+}
+
+// Interceptor is an alexa.RequestInterceptor that records a visit at the
+// start of each session, and adds the lifecycle of the user to the context
+// of every request.
+type Interceptor struct {
+	Persistence alexa.PersistenceAdapter
+	// Key is the persistent attribute to use. Default value is DefaultKey.
+	Key string
+	// Location returns the time zone used to count days, such as the
+	// Location of a schedule.Scheduler. Default value is UTC.
+	Location func(ctx context.Context) *time.Location
+	Now      func() time.Time
+}
+
+// NewInterceptor creates an Interceptor storing lifecycles in persistence.
+func NewInterceptor(persistence alexa.PersistenceAdapter) *Interceptor {
+	return &Interceptor{Persistence: persistence}
+}
+
+// InterceptRequest updates the lifecycle of the user if the session is new.
+// Otherwise the lifecycle is only read when a handler calls FromContext.
+func (i *Interceptor) InterceptRequest(ctx context.Context, requestEnv *alexa.RequestEnvelope) (context.Context, error) {
+	userID := alexa.UserIDFromContext(ctx)
+	if userID == "" {
+		return ctx, nil
+	}
+
+	if requestEnv.Session == nil || !requestEnv.Session.New {
+		l := &lazyAttributes{load: func() (*Attributes, error) {
+			a, _, err := i.load(ctx, userID)
+			return a, err
+		}}
+		return context.WithValue(ctx, lifecycleKey{}, l), nil
+	}
+
+	a, attributes, err := i.load(ctx, userID)
+	if err != nil {
+		return ctx, err
+	}
+	a.visit(i.now(ctx))
+	attributes[i.key()] = a
+	if err := i.Persistence.SaveAttributes(ctx, userID, attributes); err != nil {
+		return ctx, err
+	}
+	l := &lazyAttributes{load: func() (*Attributes, error) { return a, nil }}
+	return context.WithValue(ctx, lifecycleKey{}, l), nil
+}
+
+// load reads the lifecycle of the user and the persistent attributes it is
+// stored in.
+func (i *Interceptor) load(ctx context.Context, userID string) (*Attributes, map[string]interface{}, error) {
+	attributes, err := i.Persistence.GetAttributes(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	a := &Attributes{}
+	if _, err := alexa.DecodeAttribute(attributes, i.key(), a); err != nil {
+		return nil, nil, err
+	}
+	return a, attributes, nil
+}
+
+func (i *Interceptor) key() string {
+	if i.Key == "" {
+		return DefaultKey
+	}
+	return i.Key
+}
+
+func (i *Interceptor) now(ctx context.Context) time.Time {
+	now := time.Now
+	if i.Now != nil {
+		now = i.Now
+	}
+	loc := time.UTC
+	if i.Location != nil {
+		loc = i.Location(ctx)
+	}
+	return now().In(loc)
+}
+
+// visit records a visit at now.
+func (a *Attributes) visit(now time.Time) {
+	if a.Visits == 0 {
+		*a = Attributes{FirstVisit: now, LastVisit: now, Visits: 1, Streak: 1}
+		return
+	}
+	switch dates.DaysBetween(a.LastVisit.In(now.Location()), now) {
+	case 0:
+	case 1:
+		a.Streak++
+	default:
+		a.Streak = 1
+	}
+	a.PreviousVisit = a.LastVisit
+	a.LastVisit = now
+	a.Visits++
+}
@@ -0,0 +1,122 @@
+package lifecycle
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	alexa "github.com/ericdaugherty/alexa-skills-kit-golang"
+)
+
+type welcomeHandler struct {
+	welcome string
+}
+
+func (h *welcomeHandler) OnSessionStarted(context.Context, *alexa.Request, *alexa.Session, *alexa.Context, *alexa.Response) error {
+	return nil
+}
+
+func (h *welcomeHandler) OnLaunch(ctx context.Context, request *alexa.Request, session *alexa.Session, aContext *alexa.Context, response *alexa.Response) error {
+	a := FromContext(ctx)
+	switch {
+	case a.IsFirstVisit():
+		h.welcome = "Welcome"
+	default:
+		h.welcome = "Welcome back"
+	}
+	return nil
+}
+
+func (h *welcomeHandler) OnIntent(context.Context, *alexa.Request, *alexa.Session, *alexa.Context, *alexa.Response) error {
+	return nil
+}
+
+func (h *welcomeHandler) OnSessionEnded(context.Context, *alexa.Request, *alexa.Session, *alexa.Context, *alexa.Response) error {
+	return nil
+}
+
+func newLaunchRequest(newSession bool) *alexa.RequestEnvelope {
+	requestEnv := &alexa.RequestEnvelope{
+		Session: &alexa.Session{New: newSession},
+		Request: &alexa.Request{Type: "LaunchRequest"},
+	}
+	requestEnv.Session.User.UserID = "amzn1.ask.account.1"
+	return requestEnv
+}
+
+func TestInterceptor(t *testing.T) {
+	persistence := alexa.NewMemoryPersistence()
+	interceptor := NewInterceptor(persistence)
+	var now time.Time
+	interceptor.Now = func() time.Time { return now }
+
+	h := &welcomeHandler{}
+	a := &alexa.Alexa{RequestHandler: h, IgnoreApplicationID: true, IgnoreTimestamp: true}
+	a.RequestInterceptors = []alexa.RequestInterceptor{interceptor}
+
+	visit := func(day, hour int, newSession bool) {
+		now = time.Date(2026, 3, day, hour, 0, 0, 0, time.UTC)
+		if _, err := a.ProcessRequest(context.Background(), newLaunchRequest(newSession)); err != nil {
+			t.Fatal("Error processing request. " + err.Error())
+		}
+	}
+
+	visit(1, 9, true)
+	if h.welcome != "Welcome" {
+		t.Error("Expected first visit welcome but was", h.welcome)
+	}
+	visit(1, 10, false)
+	visit(1, 20, true)
+	visit(2, 8, true)
+	visit(3, 8, true)
+	if h.welcome != "Welcome back" {
+		t.Error("Expected returning welcome but was", h.welcome)
+	}
+
+	requestEnv := newLaunchRequest(false)
+	ctx, err := interceptor.InterceptRequest(alexa.WithRequestEnvelope(context.Background(), requestEnv), requestEnv)
+	if err != nil {
+		t.Fatal("Error loading lifecycle. " + err.Error())
+	}
+	l := FromContext(ctx)
+	if l.Visits != 4 || l.Streak != 3 {
+		t.Errorf("Expected 4 visits and a streak of 3 but were %d and %d", l.Visits, l.Streak)
+	}
+
+	now = time.Date(2026, 3, 7, 8, 0, 0, 0, time.UTC)
+	requestEnv = newLaunchRequest(true)
+	ctx, _ = interceptor.InterceptRequest(alexa.WithRequestEnvelope(context.Background(), requestEnv), requestEnv)
+	l = FromContext(ctx)
+	if l.Streak != 1 || l.DaysSincePreviousVisit() != 4 || !l.FirstVisit.Equal(time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)) {
+		t.Error("Expected the streak to restart after a gap but lifecycle was", l)
+	}
+}
+
+type countingPersistence struct {
+	alexa.PersistenceAdapter
+	gets int
+}
+
+func (p *countingPersistence) GetAttributes(ctx context.Context, userID string) (map[string]interface{}, error) {
+	p.gets++
+	return p.PersistenceAdapter.GetAttributes(ctx, userID)
+}
+
+func TestInterceptorReadsLazilyWithinSession(t *testing.T) {
+	persistence := &countingPersistence{PersistenceAdapter: alexa.NewMemoryPersistence()}
+	interceptor := NewInterceptor(persistence)
+
+	requestEnv := newLaunchRequest(false)
+	ctx, err := interceptor.InterceptRequest(alexa.WithRequestEnvelope(context.Background(), requestEnv), requestEnv)
+	if err != nil {
+		t.Fatal("Error intercepting request. " + err.Error())
+	}
+	if persistence.gets != 0 {
+		t.Errorf("Expected no reads before FromContext but there were %d", persistence.gets)
+	}
+	FromContext(ctx)
+	FromContext(ctx)
+	if persistence.gets != 1 {
+		t.Errorf("Expected 1 read but there were %d", persistence.gets)
+	}
+}
@@ -0,0 +1,105 @@
+package alexa
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tokenExpiryMargin is subtracted from the token lifetime so that a cached
+// token is never used right as it expires.
+const tokenExpiryMargin = time.Minute
+
+// ErrLWACredentialsMissing reports that an LWATokenSource has no client ID or secret.
+var ErrLWACredentialsMissing = errors.New("lwa: client id and client secret are required")
+
+// LWATokenSource obtains and caches access tokens from Login with Amazon for
+// calls made outside of a request. If RefreshToken is set it is exchanged for
+// an access token, as required by the Skill Management API. Otherwise the
+// client credentials grant is used with Scope, such as
+// "alexa::proactive_events" or "alexa:skill_messaging".
+type LWATokenSource struct {
+	ClientID     string
+	ClientSecret string
+	Scope        string
+	RefreshToken string
+	Region       Region
+	HTTPClient   *http.Client
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+// Token returns a valid access token, requesting a new one from LWA if the
+// cached token has expired.
+func (s *LWATokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && time.Now().Before(s.expiry) {
+		return s.token, nil
+	}
+	if s.ClientID == "" || s.ClientSecret == "" {
+		return "", ErrLWACredentialsMissing
+	}
+
+	form := url.Values{}
+	form.Set("client_id", s.ClientID)
+	form.Set("client_secret", s.ClientSecret)
+	if s.RefreshToken != "" {
+		form.Set("grant_type", "refresh_token")
+		form.Set("refresh_token", s.RefreshToken)
+	} else {
+		form.Set("grant_type", "client_credentials")
+		form.Set("scope", s.Scope)
+	}
+
+	endpoint := s.Region.TokenEndpoint
+	if endpoint == "" {
+		endpoint = RegionNA.TokenEndpoint
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	httpClient := s.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	var body struct {
+		AccessToken      string `json:"access_token"`
+		ExpiresIn        int    `json:"expires_in"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	json.Unmarshal(b, &body)
+	if resp.StatusCode != http.StatusOK {
+		return "", &APIError{StatusCode: resp.StatusCode, Type: body.Error, Message: body.ErrorDescription}
+	}
+	if body.AccessToken == "" {
+		return "", errors.New("lwa: response did not contain an access token")
+	}
+
+	s.token = body.AccessToken
+	s.expiry = time.Now().Add(time.Duration(body.ExpiresIn)*time.Second - tokenExpiryMargin)
+	return s.token, nil
+}
@@ -0,0 +1,217 @@
+package alexa
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// SkillManifest contains the skill manifest, as found in skill.json.
+type SkillManifest struct {
+	Manifest Manifest `json:"manifest"`
+}
+
+// Manifest contains the body of the skill manifest. Sections of the manifest
+// that the SDK does not use are kept as raw JSON so they round trip unchanged.
+type Manifest struct {
+	ManifestVersion       string                     `json:"manifestVersion"`
+	PublishingInformation *PublishingInformation     `json:"publishingInformation,omitempty"`
+	APIs                  map[string]json.RawMessage `json:"apis,omitempty"`
+	Permissions           []ManifestPermission       `json:"permissions,omitempty"`
+	PrivacyAndCompliance  json.RawMessage            `json:"privacyAndCompliance,omitempty"`
+	Events                json.RawMessage            `json:"events,omitempty"`
+}
+
+// PublishingInformation contains the store listing of the skill.
+type PublishingInformation struct {
+	Locales               map[string]LocaleInformation `json:"locales"`
+	IsAvailableWorldwide  bool                         `json:"isAvailableWorldwide"`
+	TestingInstructions   string                       `json:"testingInstructions,omitempty"`
+	Category              string                       `json:"category,omitempty"`
+	DistributionCountries []string                     `json:"distributionCountries"`
+}
+
+// LocaleInformation contains the store listing of the skill for one locale.
+type LocaleInformation struct {
+	Name           string   `json:"name"`
+	Summary        string   `json:"summary,omitempty"`
+	Description    string   `json:"description,omitempty"`
+	ExamplePhrases []string `json:"examplePhrases,omitempty"`
+	Keywords       []string `json:"keywords,omitempty"`
+	SmallIconURI   string   `json:"smallIconUri,omitempty"`
+	LargeIconURI   string   `json:"largeIconUri,omitempty"`
+}
+
+// ManifestPermission names a permission requested by the skill.
+type ManifestPermission struct {
+	Name string `json:"name"`
+}
+
+// InteractionModel contains the interaction model for one locale, as found in
+// interaction.json.
+type InteractionModel struct {
+	InteractionModel struct {
+		LanguageModel LanguageModel   `json:"languageModel"`
+		Dialog        json.RawMessage `json:"dialog,omitempty"`
+		Prompts       json.RawMessage `json:"prompts,omitempty"`
+	} `json:"interactionModel"`
+}
+
+// The model types keep the fields they do not define, such as
+// modelConfiguration or valueSupplier, in Extra, and write them back, so a
+// model read, changed and updated keeps its whole configuration.
+
+// LanguageModel contains the invocation name, intents and slot types of an
+// interaction model.
+type LanguageModel struct {
+	InvocationName string                     `json:"invocationName"`
+	Intents        []ModelIntent              `json:"intents"`
+	Types          []ModelSlotType            `json:"types,omitempty"`
+	Extra          map[string]json.RawMessage `json:"-"`
+}
+
+// ModelIntent defines an intent and its sample utterances.
+type ModelIntent struct {
+	Name    string                     `json:"name"`
+	Slots   []ModelSlot                `json:"slots,omitempty"`
+	Samples []string                   `json:"samples,omitempty"`
+	Extra   map[string]json.RawMessage `json:"-"`
+}
+
+// ModelSlot defines a slot of an intent.
+type ModelSlot struct {
+	Name    string                     `json:"name"`
+	Type    string                     `json:"type"`
+	Samples []string                   `json:"samples,omitempty"`
+	Extra   map[string]json.RawMessage `json:"-"`
+}
+
+// ModelSlotType defines a custom slot type and its values.
+type ModelSlotType struct {
+	Name   string `json:"name"`
+	Values []struct {
+		ID   string `json:"id,omitempty"`
+		Name struct {
+			Value    string   `json:"value"`
+			Synonyms []string `json:"synonyms,omitempty"`
+		} `json:"name"`
+	} `json:"values,omitempty"`
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON keeps the fields not defined in Extra.
+func (m *LanguageModel) UnmarshalJSON(b []byte) error {
+	type languageModel LanguageModel
+	return unmarshalExtra(b, (*languageModel)(m), &m.Extra)
+}
+
+// MarshalJSON writes the fields in Extra.
+func (m LanguageModel) MarshalJSON() ([]byte, error) {
+	type languageModel LanguageModel
+	return marshalExtra(languageModel(m), m.Extra)
+}
+
+// UnmarshalJSON keeps the fields not defined in Extra.
+func (m *ModelIntent) UnmarshalJSON(b []byte) error {
+	type modelIntent ModelIntent
+	return unmarshalExtra(b, (*modelIntent)(m), &m.Extra)
+}
+
+// MarshalJSON writes the fields in Extra.
+func (m ModelIntent) MarshalJSON() ([]byte, error) {
+	type modelIntent ModelIntent
+	return marshalExtra(modelIntent(m), m.Extra)
+}
+
+// UnmarshalJSON keeps the fields not defined in Extra.
+func (m *ModelSlot) UnmarshalJSON(b []byte) error {
+	type modelSlot ModelSlot
+	return unmarshalExtra(b, (*modelSlot)(m), &m.Extra)
+}
+
+// MarshalJSON writes the fields in Extra.
+func (m ModelSlot) MarshalJSON() ([]byte, error) {
+	type modelSlot ModelSlot
+	return marshalExtra(modelSlot(m), m.Extra)
+}
+
+// UnmarshalJSON keeps the fields not defined in Extra.
+func (m *ModelSlotType) UnmarshalJSON(b []byte) error {
+	type modelSlotType ModelSlotType
+	return unmarshalExtra(b, (*modelSlotType)(m), &m.Extra)
+}
+
+// MarshalJSON writes the fields in Extra.
+func (m ModelSlotType) MarshalJSON() ([]byte, error) {
+	type modelSlotType ModelSlotType
+	return marshalExtra(modelSlotType(m), m.Extra)
+}
+
+// unmarshalExtra decodes b into v, a struct without an UnmarshalJSON method,
+// and the fields of b that v does not encode into extra.
+func unmarshalExtra(b []byte, v interface{}, extra *map[string]json.RawMessage) error {
+	if err := json.Unmarshal(b, v); err != nil {
+		return err
+	}
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return err
+	}
+	t := reflect.TypeOf(v).Elem()
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name == "" {
+			name = t.Field(i).Name
+		}
+		delete(fields, name)
+	}
+	*extra = nil
+	if len(fields) > 0 {
+		*extra = fields
+	}
+	return nil
+}
+
+// marshalExtra encodes v, a struct without a MarshalJSON method, with the
+// fields of extra it does not have.
+func marshalExtra(v interface{}, extra map[string]json.RawMessage) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil || len(extra) == 0 {
+		return b, err
+	}
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, err
+	}
+	for name, value := range extra {
+		if _, ok := fields[name]; !ok {
+			fields[name] = value
+		}
+	}
+	return json.Marshal(fields)
+}
+
+// ReadInteractionModel reads an interaction model from a JSON file.
+func ReadInteractionModel(path string) (*InteractionModel, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	model := &InteractionModel{}
+	if err := json.Unmarshal(b, model); err != nil {
+		return nil, err
+	}
+	return model, nil
+}
+
+// Intent returns the intent with the given name, or nil if the model does not
+// define it.
+func (m *InteractionModel) Intent(name string) *ModelIntent {
+	for i := range m.InteractionModel.LanguageModel.Intents {
+		if m.InteractionModel.LanguageModel.Intents[i].Name == name {
+			return &m.InteractionModel.LanguageModel.Intents[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package alexa
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestReadInteractionModel(t *testing.T) {
+	model, err := ReadInteractionModel("samples/helloworld/interaction.json")
+	if err != nil {
+		t.Fatal("Error reading interaction model. " + err.Error())
+	}
+	if model.InteractionModel.LanguageModel.InvocationName != "hello world" {
+		t.Error("Expected invocation name to be 'hello world' but was", model.InteractionModel.LanguageModel.InvocationName)
+	}
+	intent := model.Intent("HelloWorldIntent")
+	if intent == nil {
+		t.Fatal("Expected model to contain HelloWorldIntent.")
+	}
+	if len(intent.Samples) != 3 {
+		t.Errorf("Expected HelloWorldIntent to have 3 samples but had %d", len(intent.Samples))
+	}
+	if model.Intent("MissingIntent") != nil {
+		t.Error("Expected MissingIntent not to be found.")
+	}
+}
+
+func TestSkillManifestRoundTrip(t *testing.T) {
+	in := `{"manifest":{"manifestVersion":"1.0","apis":{"custom":{"endpoint":{"uri":"arn:aws:lambda:TBD"}}},"events":{"subscriptions":[]}}}`
+
+	var manifest SkillManifest
+	if err := json.Unmarshal([]byte(in), &manifest); err != nil {
+		t.Fatal("Error decoding manifest. " + err.Error())
+	}
+	b, err := json.Marshal(manifest)
+	if err != nil {
+		t.Fatal("Error encoding manifest. " + err.Error())
+	}
+	if string(b) != in {
+		t.Errorf("Expected manifest to round trip as %s but was %s", in, string(b))
+	}
+}
+
+func TestInteractionModelRoundTrip(t *testing.T) {
+	in := `{"interactionModel":{"languageModel":{"intents":[{"name":"OrderIntent","slots":[{"multipleValues":{"enabled":true},"name":"item","type":"Item"}],"samples":["order {item}"]},{"name":"AMAZON.StopIntent"}],"invocationName":"shop","modelConfiguration":{"fallbackIntentSensitivity":{"level":"LOW"}},"types":[{"name":"Item","valueSupplier":{"type":"CatalogValueSupplier"}}]}}}`
+
+	var model InteractionModel
+	if err := json.Unmarshal([]byte(in), &model); err != nil {
+		t.Fatal("Error decoding model. " + err.Error())
+	}
+	if model.Intent("OrderIntent").Slots[0].Type != "Item" {
+		t.Error("Expected the slot to be decoded but was", model.Intent("OrderIntent").Slots)
+	}
+	b, err := json.Marshal(model)
+	if err != nil {
+		t.Fatal("Error encoding model. " + err.Error())
+	}
+	if string(b) != in {
+		t.Errorf("Expected model to round trip as %s but was %s", in, string(b))
+	}
+}
@@ -0,0 +1,249 @@
+package music
+
+// Attribute types of SelectionCriteria.
+const (
+	AttributeArtist    = "ARTIST"
+	AttributeAlbum     = "ALBUM"
+	AttributeTrack     = "TRACK"
+	AttributeGenre     = "GENRE"
+	AttributeStation   = "STATION"
+	AttributePlaylist  = "PLAYLIST"
+	AttributeMediaType = "MEDIA_TYPE"
+)
+
+// Metadata types of content and items.
+const (
+	MetadataTrack    = "TRACK"
+	MetadataAlbum    = "ALBUM"
+	MetadataArtist   = "ARTIST"
+	MetadataStation  = "STATION"
+	MetadataPlaylist = "PLAYLIST"
+)
+
+// PlaybackMethodQueue is the playback method of a queue of items fetched
+// with GetNextItem and GetPreviousItem.
+const PlaybackMethodQueue = "ALEXA_AUDIO_PLAYER_QUEUE"
+
+// RequestContext identifies the user and device of a request.
+type RequestContext struct {
+	User     User      `json:"user"`
+	Location *Location `json:"location,omitempty"`
+}
+
+// User is the Alexa user, with the access token of the linked account.
+type User struct {
+	ID          string `json:"id"`
+	AccessToken string `json:"accessToken,omitempty"`
+}
+
+// Location is the locale of the device.
+type Location struct {
+	OriginatingLocale string `json:"originatingLocale"`
+}
+
+// Filters restrict the content that may be played.
+type Filters struct {
+	ExplicitLanguageAllowed bool `json:"explicitLanguageAllowed"`
+}
+
+// Attribute is part of what the user asked for. EntityID is the catalog ID
+// of a resolved entity; Value is set for MEDIA_TYPE.
+type Attribute struct {
+	Type     string `json:"type"`
+	EntityID string `json:"entityId,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Value    string `json:"value,omitempty"`
+}
+
+// SelectionCriteria is what the user asked for.
+type SelectionCriteria struct {
+	Attributes []Attribute `json:"attributes"`
+}
+
+// Attribute returns the first attribute of type t.
+func (c SelectionCriteria) Attribute(t string) (Attribute, bool) {
+	for _, a := range c.Attributes {
+		if a.Type == t {
+			return a, true
+		}
+	}
+	return Attribute{}, false
+}
+
+// Speech is how a name is spoken.
+type Speech struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// Name is how content is spoken and displayed.
+type Name struct {
+	Speech  Speech `json:"speech"`
+	Display string `json:"display"`
+}
+
+// NewName returns a Name spoken and displayed as text.
+func NewName(text string) Name {
+	return Name{Speech: Speech{Type: "PLAIN_TEXT", Text: text}, Display: text}
+}
+
+// Art is the cover art of content, as images of various sizes.
+type Art struct {
+	ContentDescription string     `json:"contentDescription,omitempty"`
+	Sources            []ArtImage `json:"sources"`
+}
+
+// ArtImage is one size of Art.
+type ArtImage struct {
+	URL          string `json:"url"`
+	Size         string `json:"size,omitempty"`
+	WidthPixels  int    `json:"widthPixels,omitempty"`
+	HeightPixels int    `json:"heightPixels,omitempty"`
+}
+
+// Entity is an artist or album in Metadata.
+type Entity struct {
+	ID   string `json:"id,omitempty"`
+	Name Name   `json:"name"`
+}
+
+// Metadata describes content or an item.
+type Metadata struct {
+	Type    string   `json:"type"`
+	Name    Name     `json:"name"`
+	Art     *Art     `json:"art,omitempty"`
+	Authors []Entity `json:"authors,omitempty"`
+	Album   *Entity  `json:"album,omitempty"`
+}
+
+// Actions are what can be done with content.
+type Actions struct {
+	Playable  bool `json:"playable"`
+	Browsable bool `json:"browsable"`
+}
+
+// Content is the result of a search, played with Initiate.
+type Content struct {
+	ID       string   `json:"id"`
+	Actions  Actions  `json:"actions"`
+	Metadata Metadata `json:"metadata"`
+}
+
+// GetPlayableContentRequest is the payload of GetPlayableContent.
+type GetPlayableContentRequest struct {
+	RequestContext    RequestContext    `json:"requestContext"`
+	Filters           Filters           `json:"filters"`
+	SelectionCriteria SelectionCriteria `json:"selectionCriteria"`
+}
+
+// GetPlayableContentResponse is the payload of GetPlayableContent.Response.
+type GetPlayableContentResponse struct {
+	Content *Content `json:"content,omitempty"`
+}
+
+// Rules of a queue or item.
+type Rules struct {
+	FeedbackEnabled bool `json:"feedbackEnabled"`
+}
+
+// Control is a playback control, such as NEXT or PREVIOUS, and whether it is
+// enabled.
+type Control struct {
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+}
+
+// Stream is the audio of an item. ValidUntil is in ISO 8601 format.
+type Stream struct {
+	ID                   string `json:"id"`
+	URI                  string `json:"uri"`
+	OffsetInMilliseconds int64  `json:"offsetInMilliseconds"`
+	ValidUntil           string `json:"validUntil,omitempty"`
+}
+
+// PlaybackInfo sets how an item is played.
+type PlaybackInfo struct {
+	Type string `json:"type"`
+}
+
+// Item is a single track of a queue.
+type Item struct {
+	ID                     string        `json:"id"`
+	PlaybackInfo           *PlaybackInfo `json:"playbackInfo,omitempty"`
+	Metadata               Metadata      `json:"metadata"`
+	DurationInMilliseconds int64         `json:"durationInMilliseconds,omitempty"`
+	Controls               []Control     `json:"controls,omitempty"`
+	Rules                  *Rules        `json:"rules,omitempty"`
+	Stream                 Stream        `json:"stream"`
+}
+
+// PlaybackMethod is the queue started by Initiate.
+type PlaybackMethod struct {
+	Type      string `json:"type"`
+	ID        string `json:"id"`
+	Rules     *Rules `json:"rules,omitempty"`
+	FirstItem *Item  `json:"firstItem"`
+}
+
+// ItemReferenceValue identifies an item of a queue.
+type ItemReferenceValue struct {
+	ID        string `json:"id"`
+	QueueID   string `json:"queueId"`
+	ContentID string `json:"contentId"`
+}
+
+// ItemReference is the item being played.
+type ItemReference struct {
+	Namespace string             `json:"namespace"`
+	Name      string             `json:"name"`
+	Value     ItemReferenceValue `json:"value"`
+}
+
+// NewItemReference returns the reference to item of queueID.
+func NewItemReference(item *Item, queueID, contentID string) ItemReference {
+	return ItemReference{
+		Namespace: NamespacePlayQueue,
+		Name:      "item",
+		Value:     ItemReferenceValue{ID: item.ID, QueueID: queueID, ContentID: contentID},
+	}
+}
+
+// InitiateRequest is the payload of Initiate.
+type InitiateRequest struct {
+	RequestContext       RequestContext `json:"requestContext"`
+	ContentID            string         `json:"contentId"`
+	CurrentItemReference *ItemReference `json:"currentItemReference,omitempty"`
+	Filters              Filters        `json:"filters"`
+}
+
+// InitiateResponse is the payload of Initiate.Response.
+type InitiateResponse struct {
+	PlaybackMethod PlaybackMethod `json:"playbackMethod"`
+}
+
+// GetItemRequest is the payload of GetNextItem and GetPreviousItem.
+type GetItemRequest struct {
+	RequestContext       RequestContext `json:"requestContext"`
+	CurrentItemReference ItemReference  `json:"currentItemReference"`
+	IsUserInitiated      bool           `json:"isUserInitiated"`
+}
+
+// GetNextItemResponse is the payload of GetNextItem.Response. Item is nil
+// when the queue is finished.
+type GetNextItemResponse struct {
+	IsQueueFinished bool  `json:"isQueueFinished"`
+	Item            *Item `json:"item,omitempty"`
+}
+
+// GetPreviousItemResponse is the payload of GetPreviousItem.Response.
+type GetPreviousItemResponse struct {
+	Item *Item `json:"item"`
+}
+
+// PlaybackReport is the payload of the playback reporting events.
+type PlaybackReport struct {
+	RequestContext       RequestContext `json:"requestContext"`
+	CurrentItemReference ItemReference  `json:"currentItemReference"`
+	OffsetInMilliseconds int64          `json:"offsetInMilliseconds"`
+}
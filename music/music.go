@@ -0,0 +1,248 @@
+// Package music implements the Music Skill API. Skill takes the place of
+// alexa.Alexa and Handler the place of alexa.RequestHandler.
+//
+// A search is answered by GetPlayableContent, the content is started with
+// Initiate, which returns the first item of a queue, and further items are
+// fetched with GetNextItem and GetPreviousItem. Playback reporting events
+// tell the skill how far each item was played. The musictest package plays
+// this flow against a Skill locally.
+package music
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+
+	"github.com/ericdaugherty/alexa-skills-kit-golang/internal/uuid"
+)
+
+const payloadVersion = "1.0"
+
+// Namespaces of the requests handled by Skill.
+const (
+	NamespaceSearch    = "Alexa.Media.Search"
+	NamespacePlayback  = "Alexa.Media.Playback"
+	NamespacePlayQueue = "Alexa.Audio.PlayQueue"
+	// NamespacePlaybackStateReporter is the namespace of playback reporting
+	// events.
+	NamespacePlaybackStateReporter = "Alexa.Audio.PlaybackStateReporter"
+)
+
+// Names of the requests handled by Skill.
+const (
+	GetPlayableContent = "GetPlayableContent"
+	Initiate           = "Initiate"
+	GetNextItem        = "GetNextItem"
+	GetPreviousItem    = "GetPreviousItem"
+)
+
+// Names of the playback reporting events.
+const (
+	PlaybackStarted  = "PlaybackStarted"
+	PlaybackStopped  = "PlaybackStopped"
+	PlaybackFinished = "PlaybackFinished"
+)
+
+// Error types returned to Alexa in an ErrorResponse.
+const (
+	ErrorContentNotFound     = "CONTENT_NOT_FOUND"
+	ErrorItemNotFound        = "ITEM_NOT_FOUND"
+	ErrorInvalidRequest      = "INVALID_REQUEST"
+	ErrorInternal            = "INTERNAL_ERROR"
+	ErrorExpiredAccessToken  = "EXPIRED_ACCESS_TOKEN"
+	ErrorInvalidAccessToken  = "INVALID_ACCESS_TOKEN"
+	ErrorUnsupportedRequest  = "UNSUPPORTED_REQUEST"
+	ErrorPremiumAccountLimit = "PREMIUM_ACCOUNT_LIMIT"
+)
+
+// ErrUnsupported is returned by BaseHandler for requests the skill does not
+// implement. It is reported to Alexa as UNSUPPORTED_REQUEST.
+var ErrUnsupported = errors.New("music: request not supported")
+
+// ErrRequestNil reports that the request was nil.
+var ErrRequestNil = errors.New("music: request was nil")
+
+// Error is returned by a Handler to send Alexa an ErrorResponse of Type.
+// Other errors are sent as INTERNAL_ERROR.
+type Error struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return "music: " + e.Type + ": " + e.Message
+}
+
+// Header identifies a request or response.
+type Header struct {
+	MessageID      string `json:"messageId"`
+	Namespace      string `json:"namespace"`
+	Name           string `json:"name"`
+	PayloadVersion string `json:"payloadVersion"`
+}
+
+// Request is passed from Alexa to a music skill. Payload is decoded by Skill
+// according to the Header.
+type Request struct {
+	Header  Header          `json:"header"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// Response is returned to Alexa.
+type Response struct {
+	Header  Header      `json:"header"`
+	Payload interface{} `json:"payload"`
+}
+
+// Handler defines the interface that must be implemented to handle music
+// requests. Each method fills in the response passed to it. Embed
+// BaseHandler to implement only the requests the skill supports.
+type Handler interface {
+	OnGetPlayableContent(context.Context, *Request, *GetPlayableContentRequest, *GetPlayableContentResponse) error
+	OnInitiate(context.Context, *Request, *InitiateRequest, *InitiateResponse) error
+	OnGetNextItem(context.Context, *Request, *GetItemRequest, *GetNextItemResponse) error
+	OnGetPreviousItem(context.Context, *Request, *GetItemRequest, *GetPreviousItemResponse) error
+	OnPlaybackReport(context.Context, *Request, *PlaybackReport) error
+}
+
+// BaseHandler returns ErrUnsupported for every request and ignores playback
+// reports.
+type BaseHandler struct{}
+
+// OnGetPlayableContent returns ErrUnsupported.
+func (BaseHandler) OnGetPlayableContent(context.Context, *Request, *GetPlayableContentRequest, *GetPlayableContentResponse) error {
+	return ErrUnsupported
+}
+
+// OnInitiate returns ErrUnsupported.
+func (BaseHandler) OnInitiate(context.Context, *Request, *InitiateRequest, *InitiateResponse) error {
+	return ErrUnsupported
+}
+
+// OnGetNextItem returns ErrUnsupported.
+func (BaseHandler) OnGetNextItem(context.Context, *Request, *GetItemRequest, *GetNextItemResponse) error {
+	return ErrUnsupported
+}
+
+// OnGetPreviousItem returns ErrUnsupported.
+func (BaseHandler) OnGetPreviousItem(context.Context, *Request, *GetItemRequest, *GetPreviousItemResponse) error {
+	return ErrUnsupported
+}
+
+// OnPlaybackReport does nothing.
+func (BaseHandler) OnPlaybackReport(context.Context, *Request, *PlaybackReport) error {
+	return nil
+}
+
+// Skill is the music counterpart of alexa.Alexa.
+type Skill struct {
+	Handler Handler
+}
+
+// ProcessRequest handles a request passed from Alexa and returns the response
+// to send back. Errors from the Handler are returned to Alexa as an
+// ErrorResponse rather than as an error.
+func (s *Skill) ProcessRequest(ctx context.Context, request *Request) (*Response, error) {
+	if request == nil {
+		return nil, ErrRequestNil
+	}
+
+	var err error
+	var payload interface{} = struct{}{}
+
+	switch request.Header.Namespace + "." + request.Header.Name {
+	case NamespaceSearch + "." + GetPlayableContent:
+		req, resp := &GetPlayableContentRequest{}, &GetPlayableContentResponse{}
+		if err = decode(request, req); err == nil {
+			err = s.Handler.OnGetPlayableContent(ctx, request, req, resp)
+		}
+		payload = resp
+	case NamespacePlayback + "." + Initiate:
+		req, resp := &InitiateRequest{}, &InitiateResponse{}
+		if err = decode(request, req); err == nil {
+			err = s.Handler.OnInitiate(ctx, request, req, resp)
+		}
+		payload = resp
+	case NamespacePlayQueue + "." + GetNextItem:
+		req, resp := &GetItemRequest{}, &GetNextItemResponse{}
+		if err = decode(request, req); err == nil {
+			err = s.Handler.OnGetNextItem(ctx, request, req, resp)
+		}
+		payload = resp
+	case NamespacePlayQueue + "." + GetPreviousItem:
+		req, resp := &GetItemRequest{}, &GetPreviousItemResponse{}
+		if err = decode(request, req); err == nil {
+			err = s.Handler.OnGetPreviousItem(ctx, request, req, resp)
+		}
+		payload = resp
+	case NamespacePlaybackStateReporter + "." + PlaybackStarted,
+		NamespacePlaybackStateReporter + "." + PlaybackStopped,
+		NamespacePlaybackStateReporter + "." + PlaybackFinished:
+		req := &PlaybackReport{}
+		if err = decode(request, req); err == nil {
+			err = s.Handler.OnPlaybackReport(ctx, request, req)
+		}
+	default:
+		err = ErrUnsupported
+	}
+
+	if err != nil {
+		log.Println("Error handling "+request.Header.Namespace+"."+request.Header.Name+".", err.Error())
+		return errorResponse(err), nil
+	}
+
+	return &Response{
+		Header:  newHeader(request.Header.Namespace, request.Header.Name+".Response"),
+		Payload: payload,
+	}, nil
+}
+
+// Invoke handles a raw Lambda payload. It implements the Handler interface of
+// the aws-lambda-go lambda package.
+func (s *Skill) Invoke(ctx context.Context, payload []byte) ([]byte, error) {
+	request := &Request{}
+	if err := json.Unmarshal(payload, request); err != nil {
+		return nil, err
+	}
+	response, err := s.ProcessRequest(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(response)
+}
+
+// NewRequest returns a request with a new message ID and payload encoded as
+// JSON.
+func NewRequest(namespace, name string, payload interface{}) (*Request, error) {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return &Request{Header: newHeader(namespace, name), Payload: b}, nil
+}
+
+func decode(request *Request, v interface{}) error {
+	if len(request.Payload) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(request.Payload, v); err != nil {
+		return &Error{Type: ErrorInvalidRequest, Message: err.Error()}
+	}
+	return nil
+}
+
+func errorResponse(err error) *Response {
+	e := &Error{Type: ErrorInternal, Message: err.Error()}
+	var target *Error
+	if errors.As(err, &target) {
+		e = target
+	} else if errors.Is(err, ErrUnsupported) {
+		e.Type = ErrorUnsupportedRequest
+	}
+	return &Response{Header: newHeader("Alexa", "ErrorResponse"), Payload: e}
+}
+
+func newHeader(namespace, name string) Header {
+	return Header{MessageID: uuid.New(), Namespace: namespace, Name: name, PayloadVersion: payloadVersion}
+}
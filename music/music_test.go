@@ -0,0 +1,111 @@
+package music_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/ericdaugherty/alexa-skills-kit-golang/music"
+	"github.com/ericdaugherty/alexa-skills-kit-golang/music/musictest"
+)
+
+// albumHandler plays an album of three tracks.
+type albumHandler struct {
+	music.BaseHandler
+	reports   []string
+	streamURI string
+}
+
+func (h *albumHandler) track(n int) *music.Item {
+	id := "track-" + strconv.Itoa(n)
+	uri := h.streamURI
+	if uri == "" {
+		uri = "https://music.example.com/" + id + ".mp3"
+	}
+	return &music.Item{
+		ID:                     id,
+		Metadata:               music.Metadata{Type: music.MetadataTrack, Name: music.NewName("Track " + strconv.Itoa(n))},
+		DurationInMilliseconds: 180000,
+		Stream:                 music.Stream{ID: id, URI: uri},
+	}
+}
+
+func (h *albumHandler) OnGetPlayableContent(ctx context.Context, r *music.Request, req *music.GetPlayableContentRequest, resp *music.GetPlayableContentResponse) error {
+	album, ok := req.SelectionCriteria.Attribute(music.AttributeAlbum)
+	if !ok || album.EntityID != "album-1" {
+		return &music.Error{Type: music.ErrorContentNotFound, Message: "no such album"}
+	}
+	resp.Content = &music.Content{
+		ID:       "album-1",
+		Actions:  music.Actions{Playable: true},
+		Metadata: music.Metadata{Type: music.MetadataAlbum, Name: music.NewName("Album")},
+	}
+	return nil
+}
+
+func (h *albumHandler) OnInitiate(ctx context.Context, r *music.Request, req *music.InitiateRequest, resp *music.InitiateResponse) error {
+	resp.PlaybackMethod = music.PlaybackMethod{Type: music.PlaybackMethodQueue, ID: "queue-" + req.ContentID, FirstItem: h.track(1)}
+	return nil
+}
+
+func (h *albumHandler) OnGetNextItem(ctx context.Context, r *music.Request, req *music.GetItemRequest, resp *music.GetNextItemResponse) error {
+	n, _ := strconv.Atoi(req.CurrentItemReference.Value.ID[len("track-"):])
+	if n == 3 {
+		resp.IsQueueFinished = true
+		return nil
+	}
+	resp.Item = h.track(n + 1)
+	return nil
+}
+
+func (h *albumHandler) OnPlaybackReport(ctx context.Context, r *music.Request, report *music.PlaybackReport) error {
+	h.reports = append(h.reports, r.Header.Name+" "+report.CurrentItemReference.Value.ID)
+	return nil
+}
+
+func TestPlayQueueFlow(t *testing.T) {
+	h := &albumHandler{}
+	player := musictest.NewPlayer(&music.Skill{Handler: h}, "access-token")
+	ctx := context.Background()
+
+	content, err := player.Search(ctx, music.Attribute{Type: music.AttributeAlbum, EntityID: "album-1"})
+	if err != nil {
+		t.Fatal("Error searching. " + err.Error())
+	}
+	items, err := player.PlayAll(ctx, content.ID, 10)
+	if err != nil {
+		t.Fatal("Error playing. " + err.Error())
+	}
+	if len(items) != 3 || items[2].ID != "track-3" {
+		t.Error("Expected 3 tracks to be played but were", items)
+	}
+	if len(h.reports) != 6 || h.reports[0] != "PlaybackStarted track-1" || h.reports[5] != "PlaybackFinished track-3" {
+		t.Error("Expected started and finished reports for each track but were", h.reports)
+	}
+	if player.Current() != nil {
+		t.Error("Expected nothing to be playing once the queue finished.")
+	}
+}
+
+func TestErrorResponses(t *testing.T) {
+	h := &albumHandler{}
+	player := musictest.NewPlayer(&music.Skill{Handler: h}, "access-token")
+	ctx := context.Background()
+
+	_, err := player.Search(ctx, music.Attribute{Type: music.AttributeAlbum, EntityID: "album-2"})
+	var e *music.Error
+	if !errors.As(err, &e) || e.Type != music.ErrorContentNotFound {
+		t.Error("Expected CONTENT_NOT_FOUND but got", err)
+	}
+
+	player.Play(ctx, "album-1")
+	if _, err := player.Previous(ctx); !errors.As(err, &e) || e.Type != music.ErrorUnsupportedRequest {
+		t.Error("Expected UNSUPPORTED_REQUEST for GetPreviousItem but got", err)
+	}
+
+	h.streamURI = "http://music.example.com/track.mp3"
+	if _, err := player.Play(ctx, "album-1"); !errors.Is(err, musictest.ErrInvalidItem) {
+		t.Error("Expected an HTTP stream to be rejected but got", err)
+	}
+}
@@ -0,0 +1,226 @@
+// Package musictest plays the Music Skill API flow against a music.Skill
+// locally, the way an Alexa device would: search, initiate, then fetch and
+// report on items of the queue. Requests and responses go through JSON, and
+// items are checked for what Alexa requires to play them.
+package musictest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ericdaugherty/alexa-skills-kit-golang/music"
+)
+
+// Errors reported by Player.
+var (
+	ErrNotPlaying     = errors.New("musictest: nothing is playing")
+	ErrNotPlayable    = errors.New("musictest: content is not playable")
+	ErrInvalidItem    = errors.New("musictest: invalid item")
+	ErrWrongResponse  = errors.New("musictest: unexpected response")
+	ErrQueueNotFinite = errors.New("musictest: queue did not finish within the limit")
+)
+
+// Player emulates an Alexa device playing a music skill.
+type Player struct {
+	Skill          *music.Skill
+	RequestContext music.RequestContext
+	Filters        music.Filters
+	// Reports lists the playback reporting events sent, such as
+	// "PlaybackStarted track-1".
+	Reports []string
+
+	queueID   string
+	contentID string
+	current   *music.Item
+}
+
+// NewPlayer creates a Player for skill, for a user with the access token of a
+// linked account.
+func NewPlayer(skill *music.Skill, accessToken string) *Player {
+	return &Player{
+		Skill: skill,
+		RequestContext: music.RequestContext{
+			User:     music.User{ID: "amzn1.ask.account.musictest", AccessToken: accessToken},
+			Location: &music.Location{OriginatingLocale: "en-US"},
+		},
+	}
+}
+
+// Current returns the item playing, or nil.
+func (p *Player) Current() *music.Item {
+	return p.current
+}
+
+// Search sends GetPlayableContent and returns the content found.
+func (p *Player) Search(ctx context.Context, attributes ...music.Attribute) (*music.Content, error) {
+	resp := &music.GetPlayableContentResponse{}
+	req := &music.GetPlayableContentRequest{
+		RequestContext:    p.RequestContext,
+		Filters:           p.Filters,
+		SelectionCriteria: music.SelectionCriteria{Attributes: attributes},
+	}
+	if err := p.call(ctx, music.NamespaceSearch, music.GetPlayableContent, req, resp); err != nil {
+		return nil, err
+	}
+	if resp.Content == nil || resp.Content.ID == "" {
+		return nil, fmt.Errorf("%w: no content", ErrWrongResponse)
+	}
+	if !resp.Content.Actions.Playable {
+		return nil, fmt.Errorf("%w: %s", ErrNotPlayable, resp.Content.ID)
+	}
+	return resp.Content, nil
+}
+
+// Play sends Initiate for contentID and starts the first item.
+func (p *Player) Play(ctx context.Context, contentID string) (*music.Item, error) {
+	resp := &music.InitiateResponse{}
+	req := &music.InitiateRequest{RequestContext: p.RequestContext, ContentID: contentID, Filters: p.Filters}
+	if err := p.call(ctx, music.NamespacePlayback, music.Initiate, req, resp); err != nil {
+		return nil, err
+	}
+	m := resp.PlaybackMethod
+	if m.Type != music.PlaybackMethodQueue || m.ID == "" {
+		return nil, fmt.Errorf("%w: playback method %q with queue ID %q", ErrWrongResponse, m.Type, m.ID)
+	}
+	p.queueID, p.contentID, p.current = m.ID, contentID, nil
+	return p.start(ctx, m.FirstItem)
+}
+
+// Finish plays the current item to the end and starts the next. It returns
+// nil when the queue is finished.
+func (p *Player) Finish(ctx context.Context) (*music.Item, error) {
+	return p.next(ctx, music.PlaybackFinished, false)
+}
+
+// Next skips to the next item, as if the user asked. It returns nil when the
+// queue is finished.
+func (p *Player) Next(ctx context.Context) (*music.Item, error) {
+	return p.next(ctx, music.PlaybackStopped, true)
+}
+
+// Previous goes back to the previous item.
+func (p *Player) Previous(ctx context.Context) (*music.Item, error) {
+	if p.current == nil {
+		return nil, ErrNotPlaying
+	}
+	if err := p.report(ctx, music.PlaybackStopped); err != nil {
+		return nil, err
+	}
+	resp := &music.GetPreviousItemResponse{}
+	req := &music.GetItemRequest{RequestContext: p.RequestContext, CurrentItemReference: p.reference(), IsUserInitiated: true}
+	if err := p.call(ctx, music.NamespacePlayQueue, music.GetPreviousItem, req, resp); err != nil {
+		return nil, err
+	}
+	return p.start(ctx, resp.Item)
+}
+
+// PlayAll plays contentID from the first item until the queue is finished,
+// and returns every item played. It fails if the queue has more than limit
+// items.
+func (p *Player) PlayAll(ctx context.Context, contentID string, limit int) ([]*music.Item, error) {
+	item, err := p.Play(ctx, contentID)
+	var items []*music.Item
+	for err == nil && item != nil {
+		if len(items) == limit {
+			return items, ErrQueueNotFinite
+		}
+		items = append(items, item)
+		item, err = p.Finish(ctx)
+	}
+	return items, err
+}
+
+func (p *Player) next(ctx context.Context, event string, userInitiated bool) (*music.Item, error) {
+	if p.current == nil {
+		return nil, ErrNotPlaying
+	}
+	if err := p.report(ctx, event); err != nil {
+		return nil, err
+	}
+	resp := &music.GetNextItemResponse{}
+	req := &music.GetItemRequest{RequestContext: p.RequestContext, CurrentItemReference: p.reference(), IsUserInitiated: userInitiated}
+	if err := p.call(ctx, music.NamespacePlayQueue, music.GetNextItem, req, resp); err != nil {
+		return nil, err
+	}
+	if resp.IsQueueFinished {
+		p.current = nil
+		return nil, nil
+	}
+	return p.start(ctx, resp.Item)
+}
+
+// start checks item can be played and reports it started.
+func (p *Player) start(ctx context.Context, item *music.Item) (*music.Item, error) {
+	if err := checkItem(item); err != nil {
+		return nil, err
+	}
+	p.current = item
+	if err := p.report(ctx, music.PlaybackStarted); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+func checkItem(item *music.Item) error {
+	switch {
+	case item == nil:
+		return fmt.Errorf("%w: no item", ErrInvalidItem)
+	case item.ID == "":
+		return fmt.Errorf("%w: no ID", ErrInvalidItem)
+	case item.Stream.ID == "" || !strings.HasPrefix(item.Stream.URI, "https://"):
+		return fmt.Errorf("%w: %s must have a stream ID and an HTTPS URI", ErrInvalidItem, item.ID)
+	case item.Metadata.Name.Display == "":
+		return fmt.Errorf("%w: %s has no name", ErrInvalidItem, item.ID)
+	}
+	return nil
+}
+
+func (p *Player) reference() music.ItemReference {
+	return music.NewItemReference(p.current, p.queueID, p.contentID)
+}
+
+func (p *Player) report(ctx context.Context, event string) error {
+	p.Reports = append(p.Reports, event+" "+p.current.ID)
+	req := &music.PlaybackReport{RequestContext: p.RequestContext, CurrentItemReference: p.reference()}
+	if event != music.PlaybackStarted {
+		req.OffsetInMilliseconds = p.current.DurationInMilliseconds
+	}
+	return p.call(ctx, music.NamespacePlaybackStateReporter, event, req, &struct{}{})
+}
+
+// call sends a request through JSON and decodes the response payload into
+// out. An ErrorResponse is returned as a *music.Error.
+func (p *Player) call(ctx context.Context, namespace, name string, payload, out interface{}) error {
+	request, err := music.NewRequest(namespace, name, payload)
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	b, err = p.Skill.Invoke(ctx, b)
+	if err != nil {
+		return err
+	}
+
+	var response struct {
+		Header  music.Header    `json:"header"`
+		Payload json.RawMessage `json:"payload"`
+	}
+	if err := json.Unmarshal(b, &response); err != nil {
+		return err
+	}
+	if response.Header.Name == "ErrorResponse" {
+		e := &music.Error{}
+		json.Unmarshal(response.Payload, e)
+		return e
+	}
+	if response.Header.Name != name+".Response" {
+		return fmt.Errorf("%w: %s to %s", ErrWrongResponse, response.Header.Name, name)
+	}
+	return json.Unmarshal(response.Payload, out)
+}
@@ -0,0 +1,80 @@
+package alexa
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+)
+
+// PersistenceAdapter stores attributes that outlive a session, such as user
+// preferences or progress, under a key, usually the user ID. Attributes must
+// be JSON-encodable, and are returned decoded from JSON.
+type PersistenceAdapter interface {
+	// GetAttributes returns the attributes stored under key, or an empty map.
+	GetAttributes(ctx context.Context, key string) (map[string]interface{}, error)
+	SaveAttributes(ctx context.Context, key string, attributes map[string]interface{}) error
+}
+
+// MemoryPersistence is a PersistenceAdapter that keeps attributes in memory,
+// for tests and local development.
+type MemoryPersistence struct {
+	mu    sync.Mutex
+	items map[string][]byte
+}
+
+// NewMemoryPersistence creates an empty MemoryPersistence.
+func NewMemoryPersistence() *MemoryPersistence {
+	return &MemoryPersistence{items: make(map[string][]byte)}
+}
+
+// GetAttributes returns a copy of the attributes stored under key.
+func (m *MemoryPersistence) GetAttributes(ctx context.Context, key string) (map[string]interface{}, error) {
+	m.mu.Lock()
+	b, ok := m.items[key]
+	m.mu.Unlock()
+
+	attributes := make(map[string]interface{})
+	if !ok {
+		return attributes, nil
+	}
+	err := json.Unmarshal(b, &attributes)
+	return attributes, err
+}
+
+// SaveAttributes stores a copy of attributes under key.
+func (m *MemoryPersistence) SaveAttributes(ctx context.Context, key string, attributes map[string]interface{}) error {
+	b, err := json.Marshal(attributes)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	m.items[key] = b
+	m.mu.Unlock()
+	return nil
+}
+
+// DecodeAttribute decodes the attribute name into v, reporting whether it is
+// set. Attributes read back from JSON, from the session or a
+// PersistenceAdapter, are maps and slices, so the value is converted through
+// JSON.
+func DecodeAttribute(attributes map[string]interface{}, name string, v interface{}) (bool, error) {
+	value, ok := attributes[name]
+	if !ok {
+		return false, nil
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// RequestInterceptor runs before the RequestHandler for every request, and
+// may return a ctx enriched with values for the handler. An error fails the
+// request.
+type RequestInterceptor interface {
+	InterceptRequest(ctx context.Context, requestEnv *RequestEnvelope) (context.Context, error)
+}
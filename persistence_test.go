@@ -0,0 +1,78 @@
+package alexa
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type greetingInterceptor struct {
+	err error
+}
+
+type greetingKey struct{}
+
+func (i *greetingInterceptor) InterceptRequest(ctx context.Context, requestEnv *RequestEnvelope) (context.Context, error) {
+	return context.WithValue(ctx, greetingKey{}, "Hi"), i.err
+}
+
+type greetingHandler struct {
+	emptyRequestHandler
+	greeting interface{}
+}
+
+func (h *greetingHandler) OnIntent(ctx context.Context, request *Request, session *Session, aContext *Context, response *Response) error {
+	h.greeting = ctx.Value(greetingKey{})
+	return nil
+}
+
+func TestRequestInterceptors(t *testing.T) {
+	h := &greetingHandler{}
+	alexa := getAlexaWithHandler(h)
+	alexa.RequestInterceptors = []RequestInterceptor{&greetingInterceptor{}}
+	if _, err := alexa.ProcessRequest(context.Background(), createRecipeRequest()); err != nil {
+		t.Fatal("Error processing request. " + err.Error())
+	}
+	if h.greeting != "Hi" {
+		t.Error("Expected interceptor to enrich the handler context but value was", h.greeting)
+	}
+
+	failure := errors.New("persistence unavailable")
+	alexa.RequestInterceptors = []RequestInterceptor{&greetingInterceptor{err: failure}}
+	if _, err := alexa.ProcessRequest(context.Background(), createRecipeRequest()); err != failure {
+		t.Error("Expected interceptor error to fail the request but got", err)
+	}
+}
+
+func TestMemoryPersistence(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemoryPersistence()
+	attributes, err := m.GetAttributes(ctx, "user")
+	if err != nil || len(attributes) != 0 {
+		t.Error("Expected no attributes for a new key but got", attributes, err)
+	}
+
+	attributes["color"] = "blue"
+	m.SaveAttributes(ctx, "user", attributes)
+	attributes["color"] = "red"
+
+	stored, _ := m.GetAttributes(ctx, "user")
+	if stored["color"] != "blue" {
+		t.Error("Expected a copy of the attributes to be stored but was", stored["color"])
+	}
+}
+
+func TestDecodeAttribute(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemoryPersistence()
+	m.SaveAttributes(ctx, "user", map[string]interface{}{"pending": Intent{Name: "OrderIntent"}})
+	attributes, _ := m.GetAttributes(ctx, "user")
+
+	var intent Intent
+	if ok, err := DecodeAttribute(attributes, "pending", &intent); !ok || err != nil || intent.Name != "OrderIntent" {
+		t.Error("Expected the stored intent to be decoded but was", intent, ok, err)
+	}
+	if ok, err := DecodeAttribute(attributes, "missing", &intent); ok || err != nil {
+		t.Error("Expected a missing attribute to be reported but got", ok, err)
+	}
+}
@@ -0,0 +1,399 @@
+// Package pin asks the user for a four digit voice code before sensitive
+// intents, such as placing an order. The code is stored hashed, with a salt
+// for each user, in an alexa.PersistenceAdapter, and the user is locked out
+// for a while after too many wrong codes.
+//
+// The interaction model needs two intents with a slot of type
+// AMAZON.FOUR_DIGIT_NUMBER: VerifyIntent, to say the code when asked, and
+// SetIntent, to set or change it. The slot should be marked with
+// alexa.SensitiveSlots so the code is not logged.
+package pin
+
+import (
+	"context"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"regexp"
+	"time"
+
+	alexa "github.com/ericdaugherty/alexa-skills-kit-golang"
+)
+
+// Names used in the interaction model.
+const (
+	VerifyIntent = "VerifyPINIntent"
+	SetIntent    = "SetPINIntent"
+	SlotName     = "PIN"
+)
+
+// Attribute names.
+const (
+	// DefaultKey is the persistent attribute the hashed code is stored under.
+	DefaultKey = "pin"
+	// VerifiedAttribute is the session attribute set once the user said the
+	// right code.
+	VerifiedAttribute = "pinVerified"
+	// PendingAttribute is the session attribute holding the sensitive intent
+	// waiting for the code.
+	PendingAttribute = "pinPending"
+)
+
+// Defaults of a Guard.
+const (
+	DefaultMaxFailures = 3
+	DefaultCooldown    = 15 * time.Minute
+	DefaultIterations  = 100000
+)
+
+var (
+	// ErrNoPIN reports that the user has not set a code.
+	ErrNoPIN = errors.New("no voice code set")
+	// ErrWrongPIN reports that the code does not match.
+	ErrWrongPIN = errors.New("wrong voice code")
+	// ErrLockedOut reports that the user made too many wrong attempts and
+	// must wait for the cooldown to end.
+	ErrLockedOut = errors.New("too many wrong voice codes")
+	// ErrInvalidPIN reports that a new code is not four digits.
+	ErrInvalidPIN = errors.New("voice code must be four digits")
+)
+
+var fourDigits = regexp.MustCompile(`^[0-9]{4}$`)
+
+// Prompts are the speech of a Guard.
+type Prompts struct {
+	Ask       string
+	Retry     string
+	Verified  string
+	LockedOut string
+	NoPIN     string
+	AskNew    string
+	Set       string
+}
+
+// DefaultPrompts are used for the Prompts left empty.
+var DefaultPrompts = Prompts{
+	Ask:       "Please say your four digit voice code.",
+	Retry:     "That voice code is not right. Please try again.",
+	Verified:  "Thanks, your voice code is verified.",
+	LockedOut: "Too many wrong voice codes were said. Please try again later.",
+	NoPIN:     "You need a voice code for that. To set one, say: set my voice code.",
+	AskNew:    "What four digit voice code would you like to use?",
+	Set:       "Your voice code is set.",
+}
+
+// record is the persistent state of the code of a user.
+type record struct {
+	Hash        []byte    `json:"hash"`
+	Salt        []byte    `json:"salt"`
+	Iterations  int       `json:"iterations"`
+	Failures    int       `json:"failures"`
+	LockedUntil time.Time `json:"lockedUntil"`
+}
+
+// Guard is an alexa.RequestHandler that asks for the voice code before
+// passing the sensitive intents to Handler. Once the code is verified, the
+// intent that asked for it is passed on, and other sensitive intents are
+// passed on for the rest of the session.
+//
+// Guard handles VerifyIntent and SetIntent. Changing a code asks for the
+// current one first.
+type Guard struct {
+	Handler     alexa.RequestHandler
+	Persistence alexa.PersistenceAdapter
+	// Intents are the names of the sensitive intents.
+	Intents map[string]bool
+	// Key is the persistent attribute to use. Default value is DefaultKey.
+	Key string
+	// MaxFailures is the number of wrong codes that lock the user out for
+	// Cooldown. Defaults are DefaultMaxFailures and DefaultCooldown.
+	MaxFailures int
+	Cooldown    time.Duration
+	// Iterations of PBKDF2 for new codes. Default value is DefaultIterations.
+	Iterations int
+	Prompts    Prompts
+	Now        func() time.Time
+}
+
+// NewGuard creates a Guard passing requests to handler, with the named
+// intents as sensitive.
+func NewGuard(handler alexa.RequestHandler, persistence alexa.PersistenceAdapter, intents ...string) *Guard {
+	g := &Guard{Handler: handler, Persistence: persistence, Intents: make(map[string]bool)}
+	for _, i := range intents {
+		g.Intents[i] = true
+	}
+	return g
+}
+
+// Verified reports whether the user said the right code in this session.
+func Verified(session *alexa.Session) bool {
+	v, _ := session.Attributes.String[VerifiedAttribute].(bool)
+	return v
+}
+
+// HasPIN reports whether the user has set a code.
+func (g *Guard) HasPIN(ctx context.Context, userID string) (bool, error) {
+	r, _, err := g.load(ctx, userID)
+	return r != nil, err
+}
+
+// SetPIN stores pin as the code of the user. It returns ErrInvalidPIN unless
+// pin is four digits.
+func (g *Guard) SetPIN(ctx context.Context, userID, pin string) error {
+	if !fourDigits.MatchString(pin) {
+		return ErrInvalidPIN
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	iterations := g.Iterations
+	if iterations == 0 {
+		iterations = DefaultIterations
+	}
+	hash, err := pbkdf2.Key(sha256.New, pin, salt, iterations, 32)
+	if err != nil {
+		return err
+	}
+	_, attributes, err := g.load(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return g.save(ctx, userID, attributes, &record{Hash: hash, Salt: salt, Iterations: iterations})
+}
+
+// Check compares pin with the code of the user. It returns ErrNoPIN,
+// ErrWrongPIN or ErrLockedOut, counting wrong codes and locking the user out
+// after MaxFailures.
+func (g *Guard) Check(ctx context.Context, userID, pin string) error {
+	r, attributes, err := g.load(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if r == nil {
+		return ErrNoPIN
+	}
+	now := g.now()
+	if now.Before(r.LockedUntil) {
+		return ErrLockedOut
+	}
+
+	hash, err := pbkdf2.Key(sha256.New, pin, r.Salt, r.Iterations, len(r.Hash))
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare(hash, r.Hash) == 1 {
+		if r.Failures > 0 || !r.LockedUntil.IsZero() {
+			r.Failures = 0
+			r.LockedUntil = time.Time{}
+			return g.save(ctx, userID, attributes, r)
+		}
+		return nil
+	}
+
+	r.Failures++
+	wrong := ErrWrongPIN
+	if r.Failures >= g.maxFailures() {
+		r.Failures = 0
+		r.LockedUntil = now.Add(g.cooldown())
+		wrong = ErrLockedOut
+	}
+	if err := g.save(ctx, userID, attributes, r); err != nil {
+		return err
+	}
+	return wrong
+}
+
+// OnSessionStarted calls Handler.
+func (g *Guard) OnSessionStarted(ctx context.Context, request *alexa.Request, session *alexa.Session, aContext *alexa.Context, response *alexa.Response) error {
+	return g.Handler.OnSessionStarted(ctx, request, session, aContext, response)
+}
+
+// OnLaunch calls Handler.
+func (g *Guard) OnLaunch(ctx context.Context, request *alexa.Request, session *alexa.Session, aContext *alexa.Context, response *alexa.Response) error {
+	return g.Handler.OnLaunch(ctx, request, session, aContext, response)
+}
+
+// OnIntent handles VerifyIntent and SetIntent, asks for the code before
+// unverified sensitive intents, and passes other intents to Handler.
+func (g *Guard) OnIntent(ctx context.Context, request *alexa.Request, session *alexa.Session, aContext *alexa.Context, response *alexa.Response) error {
+	switch request.Intent.Name {
+	case VerifyIntent:
+		return g.verify(ctx, request, session, aContext, response)
+	case SetIntent:
+		return g.set(ctx, request, session, response)
+	}
+	if g.Intents[request.Intent.Name] && !Verified(session) {
+		return g.challenge(ctx, request.Intent, session, response)
+	}
+	return g.Handler.OnIntent(ctx, request, session, aContext, response)
+}
+
+// OnSessionResumed calls Handler, if it is an alexa.SessionResumedHandler.
+func (g *Guard) OnSessionResumed(ctx context.Context, request *alexa.Request, session *alexa.Session, aContext *alexa.Context, response *alexa.Response) error {
+	if h, ok := g.Handler.(alexa.SessionResumedHandler); ok {
+		return h.OnSessionResumed(ctx, request, session, aContext, response)
+	}
+	return nil
+}
+
+// OnSessionEnded calls Handler.
+func (g *Guard) OnSessionEnded(ctx context.Context, request *alexa.Request, session *alexa.Session, aContext *alexa.Context, response *alexa.Response) error {
+	return g.Handler.OnSessionEnded(ctx, request, session, aContext, response)
+}
+
+// challenge asks for the code, keeping intent until it is verified.
+func (g *Guard) challenge(ctx context.Context, intent alexa.Intent, session *alexa.Session, response *alexa.Response) error {
+	r, _, err := g.load(ctx, session.User.UserID)
+	if err != nil {
+		return err
+	}
+	switch {
+	case r == nil:
+		g.ask(response, g.prompts().NoPIN)
+	case g.now().Before(r.LockedUntil):
+		response.SetOutputText(g.prompts().LockedOut)
+	default:
+		session.Attributes.String[PendingAttribute] = intent
+		g.ask(response, g.prompts().Ask)
+	}
+	return nil
+}
+
+// verify checks the code said for VerifyIntent, then passes on the pending
+// intent, if any.
+func (g *Guard) verify(ctx context.Context, request *alexa.Request, session *alexa.Session, aContext *alexa.Context, response *alexa.Response) error {
+	err := g.Check(ctx, session.User.UserID, request.Intent.Slots[SlotName].Value)
+	switch err {
+	case nil:
+	case ErrNoPIN:
+		delete(session.Attributes.String, PendingAttribute)
+		g.ask(response, g.prompts().NoPIN)
+		return nil
+	case ErrWrongPIN:
+		g.ask(response, g.prompts().Retry)
+		return nil
+	case ErrLockedOut:
+		delete(session.Attributes.String, PendingAttribute)
+		response.SetOutputText(g.prompts().LockedOut)
+		return nil
+	default:
+		return err
+	}
+
+	session.Attributes.String[VerifiedAttribute] = true
+	var pending alexa.Intent
+	ok, err := alexa.DecodeAttribute(session.Attributes.String, PendingAttribute, &pending)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		g.ask(response, g.prompts().Verified)
+		return nil
+	}
+	delete(session.Attributes.String, PendingAttribute)
+	resumed := *request
+	resumed.Intent = pending
+	return g.OnIntent(ctx, &resumed, session, aContext, response)
+}
+
+// set asks for and stores a new code, asking for the current code first.
+func (g *Guard) set(ctx context.Context, request *alexa.Request, session *alexa.Session, response *alexa.Response) error {
+	userID := session.User.UserID
+	exists, err := g.HasPIN(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if exists && !Verified(session) {
+		return g.challenge(ctx, alexa.Intent{Name: SetIntent}, session, response)
+	}
+
+	pin := request.Intent.Slots[SlotName].Value
+	if !fourDigits.MatchString(pin) {
+		intent := request.Intent
+		response.AddDialogDirective("Dialog.ElicitSlot", SlotName, "", &intent)
+		g.ask(response, g.prompts().AskNew)
+		return nil
+	}
+	if err := g.SetPIN(ctx, userID, pin); err != nil {
+		return err
+	}
+	session.Attributes.String[VerifiedAttribute] = true
+	g.ask(response, g.prompts().Set)
+	return nil
+}
+
+func (g *Guard) ask(response *alexa.Response, text string) {
+	response.SetOutputText(text)
+	response.SetRepromptText(text)
+	response.ShouldSessionEnd = false
+}
+
+// load returns the record of the user, or nil, and all their attributes.
+func (g *Guard) load(ctx context.Context, userID string) (*record, map[string]interface{}, error) {
+	attributes, err := g.Persistence.GetAttributes(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	r := &record{}
+	if ok, err := alexa.DecodeAttribute(attributes, g.key(), r); err != nil || !ok {
+		return nil, attributes, err
+	}
+	return r, attributes, nil
+}
+
+func (g *Guard) save(ctx context.Context, userID string, attributes map[string]interface{}, r *record) error {
+	attributes[g.key()] = r
+	return g.Persistence.SaveAttributes(ctx, userID, attributes)
+}
+
+func (g *Guard) key() string {
+	if g.Key == "" {
+		return DefaultKey
+	}
+	return g.Key
+}
+
+func (g *Guard) maxFailures() int {
+	if g.MaxFailures == 0 {
+		return DefaultMaxFailures
+	}
+	return g.MaxFailures
+}
+
+func (g *Guard) cooldown() time.Duration {
+	if g.Cooldown == 0 {
+		return DefaultCooldown
+	}
+	return g.Cooldown
+}
+
+func (g *Guard) now() time.Time {
+	if g.Now == nil {
+		return time.Now()
+	}
+	return g.Now()
+}
+
+// prompts returns Prompts with DefaultPrompts for the empty ones.
+func (g *Guard) prompts() Prompts {
+	p, d := g.Prompts, DefaultPrompts
+	return Prompts{
+		Ask:       or(p.Ask, d.Ask),
+		Retry:     or(p.Retry, d.Retry),
+		Verified:  or(p.Verified, d.Verified),
+		LockedOut: or(p.LockedOut, d.LockedOut),
+		NoPIN:     or(p.NoPIN, d.NoPIN),
+		AskNew:    or(p.AskNew, d.AskNew),
+		Set:       or(p.Set, d.Set),
+	}
+}
+
+func or(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
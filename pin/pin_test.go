@@ -0,0 +1,148 @@
+package pin
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	alexa "github.com/ericdaugherty/alexa-skills-kit-golang"
+)
+
+type orderHandler struct {
+	orders int
+}
+
+func (h *orderHandler) OnSessionStarted(context.Context, *alexa.Request, *alexa.Session, *alexa.Context, *alexa.Response) error {
+	return nil
+}
+
+func (h *orderHandler) OnLaunch(context.Context, *alexa.Request, *alexa.Session, *alexa.Context, *alexa.Response) error {
+	return nil
+}
+
+func (h *orderHandler) OnIntent(ctx context.Context, request *alexa.Request, session *alexa.Session, aContext *alexa.Context, response *alexa.Response) error {
+	h.orders++
+	response.SetOutputText("Order placed.")
+	return nil
+}
+
+func (h *orderHandler) OnSessionEnded(context.Context, *alexa.Request, *alexa.Session, *alexa.Context, *alexa.Response) error {
+	return nil
+}
+
+// conversation sends intents in one session, keeping session attributes as
+// Alexa would.
+type conversation struct {
+	t          *testing.T
+	a          *alexa.Alexa
+	attributes map[string]interface{}
+}
+
+func newConversation(t *testing.T, g *Guard) *conversation {
+	return &conversation{t: t, a: &alexa.Alexa{RequestHandler: g, IgnoreApplicationID: true, IgnoreTimestamp: true}}
+}
+
+func (c *conversation) say(intent, pin string) string {
+	requestEnv := &alexa.RequestEnvelope{
+		Session: &alexa.Session{New: c.attributes == nil},
+		Request: &alexa.Request{Type: "IntentRequest", Intent: alexa.Intent{Name: intent}},
+	}
+	requestEnv.Session.User.UserID = "amzn1.ask.account.1"
+	requestEnv.Session.Attributes.String = c.attributes
+	if pin != "" {
+		requestEnv.Request.Intent.Slots = map[string]alexa.IntentSlot{SlotName: {Name: SlotName, Value: pin}}
+	}
+
+	responseEnv, err := c.a.ProcessRequest(context.Background(), requestEnv)
+	if err != nil {
+		c.t.Fatal("Error processing request. " + err.Error())
+	}
+	b, _ := json.Marshal(responseEnv.SessionAttributes)
+	c.attributes = make(map[string]interface{})
+	json.Unmarshal(b, &c.attributes)
+	return responseEnv.Response.OutputSpeech.Text
+}
+
+func TestGuard(t *testing.T) {
+	h := &orderHandler{}
+	g := NewGuard(h, alexa.NewMemoryPersistence(), "PlaceOrderIntent")
+	g.Iterations = 1000
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	g.Now = func() time.Time { return now }
+
+	c := newConversation(t, g)
+	if text := c.say("PlaceOrderIntent", ""); text != DefaultPrompts.NoPIN || h.orders != 0 {
+		t.Error("Expected to be asked to set a code but was", text)
+	}
+	if text := c.say(SetIntent, ""); text != DefaultPrompts.AskNew {
+		t.Error("Expected to be asked for a new code but was", text)
+	}
+	if text := c.say(SetIntent, "4821"); text != DefaultPrompts.Set {
+		t.Error("Expected code to be set but was", text)
+	}
+
+	c = newConversation(t, g)
+	if text := c.say("PlaceOrderIntent", ""); text != DefaultPrompts.Ask || h.orders != 0 {
+		t.Error("Expected to be asked for the code but was", text)
+	}
+	if text := c.say(VerifyIntent, "1111"); text != DefaultPrompts.Retry {
+		t.Error("Expected to be asked to retry but was", text)
+	}
+	if text := c.say(VerifyIntent, "4821"); text != "Order placed." || h.orders != 1 {
+		t.Error("Expected the pending order to be placed but was", text)
+	}
+	if text := c.say("PlaceOrderIntent", ""); text != "Order placed." || h.orders != 2 {
+		t.Error("Expected the session to stay verified but was", text)
+	}
+
+	c = newConversation(t, g)
+	if text := c.say(SetIntent, "1234"); text != DefaultPrompts.Ask {
+		t.Error("Expected to be asked for the current code before changing it but was", text)
+	}
+	if text := c.say(VerifyIntent, "4821"); text != DefaultPrompts.AskNew {
+		t.Error("Expected to be asked for a new code but was", text)
+	}
+	if text := c.say(SetIntent, "1234"); text != DefaultPrompts.Set {
+		t.Error("Expected code to be changed but was", text)
+	}
+	if err := g.Check(context.Background(), "amzn1.ask.account.1", "1234"); err != nil {
+		t.Error("Expected the new code to be valid but got", err)
+	}
+}
+
+func TestGuardLockout(t *testing.T) {
+	ctx := context.Background()
+	g := NewGuard(&orderHandler{}, alexa.NewMemoryPersistence())
+	g.Iterations = 1000
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	g.Now = func() time.Time { return now }
+
+	if err := g.SetPIN(ctx, "user", "12345"); err != ErrInvalidPIN {
+		t.Error("Expected five digits to be rejected but got", err)
+	}
+	if err := g.Check(ctx, "user", "1234"); err != ErrNoPIN {
+		t.Error("Expected ErrNoPIN but got", err)
+	}
+	g.SetPIN(ctx, "user", "0007")
+
+	for i := 0; i < DefaultMaxFailures-1; i++ {
+		if err := g.Check(ctx, "user", "7000"); err != ErrWrongPIN {
+			t.Error("Expected ErrWrongPIN but got", err)
+		}
+	}
+	if err := g.Check(ctx, "user", "7000"); err != ErrLockedOut {
+		t.Error("Expected the last failure to lock out but got", err)
+	}
+	if err := g.Check(ctx, "user", "0007"); err != ErrLockedOut {
+		t.Error("Expected the right code to be refused during the cooldown but got", err)
+	}
+
+	now = now.Add(DefaultCooldown)
+	if err := g.Check(ctx, "user", "0007"); err != nil {
+		t.Error("Expected the right code after the cooldown but got", err)
+	}
+	if err := g.Check(ctx, "user", "7000"); err != ErrWrongPIN {
+		t.Error("Expected failures to be reset but got", err)
+	}
+}
@@ -0,0 +1,50 @@
+package alexa
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// ProactiveEvent is a notification sent with the Proactive Events API. The
+// ServiceClient needs an LWA token with the alexa::proactive_events scope.
+type ProactiveEvent struct {
+	Timestamp   time.Time `json:"timestamp"`
+	ReferenceID string    `json:"referenceId"`
+	ExpiryTime  time.Time `json:"expiryTime"`
+	Event       struct {
+		// Name is the schema of the event, such as AMAZON.MessageAlert.Activated.
+		Name    string      `json:"name"`
+		Payload interface{} `json:"payload"`
+	} `json:"event"`
+	LocalizedAttributes []map[string]string `json:"localizedAttributes,omitempty"`
+	RelevantAudience    struct {
+		// Type is Unicast for one user, with a payload of {"user": userID},
+		// or Multicast for all subscribed users.
+		Type    string      `json:"type"`
+		Payload interface{} `json:"payload"`
+	} `json:"relevantAudience"`
+}
+
+// SendProactiveEvent sends event to users. Unless live is set, it is sent to
+// the development stage of the skill.
+func (c *ServiceClient) SendProactiveEvent(ctx context.Context, event *ProactiveEvent, live bool) error {
+	path := "/v1/proactiveEvents/stages/development"
+	if live {
+		path = "/v1/proactiveEvents"
+	}
+	return c.Do(ctx, http.MethodPost, path, event, nil)
+}
+
+// SendSkillMessage sends data to the skill with the Skill Messaging API, for
+// the user. The skill receives it as a Messaging.MessageReceived request,
+// unless it expires first. The ServiceClient needs an LWA token with the
+// alexa:skill_messaging scope.
+func (c *ServiceClient) SendSkillMessage(ctx context.Context, userID string, data interface{}, expiresAfter time.Duration) error {
+	body := struct {
+		Data                interface{} `json:"data"`
+		ExpiresAfterSeconds int         `json:"expiresAfterSeconds"`
+	}{data, int(expiresAfter / time.Second)}
+	return c.Do(ctx, http.MethodPost, "/v1/skillmessages/users/"+url.PathEscape(userID), body, nil)
+}
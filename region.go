@@ -0,0 +1,121 @@
+package alexa
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Region contains the endpoints of the Alexa APIs and of Login with Amazon
+// (LWA) for one of the regions Alexa is hosted in. The endpoints may be
+// overridden on a copy of a Region, for example to call a local fake.
+type Region struct {
+	Name          string
+	APIEndpoint   string
+	TokenEndpoint string
+}
+
+// RegionNA is North America.
+var RegionNA = Region{
+	Name:          "NA",
+	APIEndpoint:   "https://api.amazonalexa.com",
+	TokenEndpoint: "https://api.amazon.com/auth/o2/token",
+}
+
+// RegionEU is Europe and India.
+var RegionEU = Region{
+	Name:          "EU",
+	APIEndpoint:   "https://api.eu.amazonalexa.com",
+	TokenEndpoint: "https://api.amazon.co.uk/auth/o2/token",
+}
+
+// RegionFE is the Far East.
+var RegionFE = Region{
+	Name:          "FE",
+	APIEndpoint:   "https://api.fe.amazonalexa.com",
+	TokenEndpoint: "https://api.amazon.co.jp/auth/o2/token",
+}
+
+// Regions lists the known regions.
+var Regions = []Region{RegionNA, RegionEU, RegionFE}
+
+var localeRegions = map[string]Region{
+	"en-US": RegionNA,
+	"en-CA": RegionNA,
+	"fr-CA": RegionNA,
+	"es-US": RegionNA,
+	"es-MX": RegionNA,
+	"pt-BR": RegionNA,
+	"en-GB": RegionEU,
+	"en-IN": RegionEU,
+	"hi-IN": RegionEU,
+	"de-DE": RegionEU,
+	"fr-FR": RegionEU,
+	"it-IT": RegionEU,
+	"es-ES": RegionEU,
+	"ar-SA": RegionEU,
+	"ja-JP": RegionFE,
+	"en-AU": RegionFE,
+}
+
+// RegionForAPIEndpoint returns the region whose API endpoint has the same host
+// as endpoint. It returns false if the endpoint is not a known Alexa endpoint.
+func RegionForAPIEndpoint(endpoint string) (Region, bool) {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return Region{}, false
+	}
+	for _, r := range Regions {
+		ru, _ := url.Parse(r.APIEndpoint)
+		if strings.EqualFold(u.Host, ru.Host) {
+			return r, true
+		}
+	}
+	return Region{}, false
+}
+
+// RegionForLocale returns the region that usually serves locale, such as
+// "de-DE". Unknown locales are served by RegionNA.
+func RegionForLocale(locale string) Region {
+	if r, ok := localeRegions[locale]; ok {
+		return r
+	}
+	for l, r := range localeRegions {
+		if strings.EqualFold(l, locale) {
+			return r
+		}
+	}
+	return RegionNA
+}
+
+// Region infers the region of the request from Context.System.APIEndpoint,
+// falling back to the request locale. If the request carries an API endpoint
+// that is not a known Alexa endpoint, such as a local fake, the returned
+// region uses it as its APIEndpoint.
+func (requestEnv *RequestEnvelope) Region() Region {
+	var endpoint, locale string
+	if requestEnv.Context != nil {
+		endpoint = requestEnv.Context.System.APIEndpoint
+	}
+	if requestEnv.Request != nil {
+		locale = requestEnv.Request.Locale
+	}
+
+	if r, ok := RegionForAPIEndpoint(endpoint); ok {
+		return r
+	}
+	r := RegionForLocale(locale)
+	if endpoint != "" {
+		r.APIEndpoint = endpoint
+	}
+	return r
+}
+
+// NewServiceClientForRegion creates a ServiceClient for calls made outside of
+// a request, such as proactive events or skill messaging, using an access
+// token obtained from LWA.
+func NewServiceClientForRegion(region Region, accessToken string) *ServiceClient {
+	return &ServiceClient{
+		Endpoint:    region.APIEndpoint,
+		AccessToken: accessToken,
+	}
+}
@@ -0,0 +1,93 @@
+package alexa
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRegionForAPIEndpoint(t *testing.T) {
+	r, ok := RegionForAPIEndpoint("https://api.eu.amazonalexa.com")
+	if !ok || r.Name != "EU" {
+		t.Error("Expected https://api.eu.amazonalexa.com to be EU but was", r.Name)
+	}
+	r, ok = RegionForAPIEndpoint("https://api.fe.amazonalexa.com/")
+	if !ok || r.Name != "FE" {
+		t.Error("Expected https://api.fe.amazonalexa.com/ to be FE but was", r.Name)
+	}
+	_, ok = RegionForAPIEndpoint("http://localhost:8080")
+	if ok {
+		t.Error("Expected a local endpoint not to match a region.")
+	}
+}
+
+func TestRegionForLocale(t *testing.T) {
+	for locale, name := range map[string]string{"en-US": "NA", "de-DE": "EU", "en-in": "EU", "ja-JP": "FE", "xx-XX": "NA"} {
+		if r := RegionForLocale(locale); r.Name != name {
+			t.Errorf("Expected locale %s to be %s but was %s", locale, name, r.Name)
+		}
+	}
+}
+
+func TestRequestEnvelopeRegion(t *testing.T) {
+	request := createRecipeRequest()
+	request.Request.Locale = "en-GB"
+
+	if r := request.Region(); r.Name != "EU" || r.APIEndpoint != RegionEU.APIEndpoint {
+		t.Errorf("Expected region to be inferred from the locale but was %s %s", r.Name, r.APIEndpoint)
+	}
+
+	request.Context.System.APIEndpoint = "https://api.fe.amazonalexa.com"
+	if r := request.Region(); r.Name != "FE" {
+		t.Error("Expected region to be inferred from the API endpoint but was", r.Name)
+	}
+
+	request.Context.System.APIEndpoint = "http://localhost:8080"
+	if r := request.Region(); r.Name != "EU" || r.APIEndpoint != "http://localhost:8080" {
+		t.Errorf("Expected local API endpoint to override the locale region but was %s %s", r.Name, r.APIEndpoint)
+	}
+}
+
+func TestLWATokenSource(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		r.ParseForm()
+		if r.Form.Get("client_secret") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error":"invalid_client","error_description":"Client authentication failed"}`))
+			return
+		}
+		if r.Form.Get("grant_type") != "client_credentials" || r.Form.Get("scope") != "alexa::proactive_events" {
+			t.Errorf("Unexpected token request grant %s scope %s", r.Form.Get("grant_type"), r.Form.Get("scope"))
+		}
+		w.Write([]byte(`{"access_token":"Atc|abc","expires_in":3600,"token_type":"bearer"}`))
+	}))
+	defer server.Close()
+
+	region := RegionNA
+	region.TokenEndpoint = server.URL
+	source := &LWATokenSource{ClientID: "id", ClientSecret: "secret", Scope: "alexa::proactive_events", Region: region}
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		token, err := source.Token(ctx)
+		if err != nil {
+			t.Fatal("Error getting token. " + err.Error())
+		}
+		if token != "Atc|abc" {
+			t.Error("Expected token to be Atc|abc but was", token)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("Expected token to be cached but LWA was called %d times", calls)
+	}
+
+	source = &LWATokenSource{ClientID: "id", ClientSecret: "wrong", Region: region}
+	_, err := source.Token(ctx)
+	if !errors.Is(err, ErrUnauthorized) {
+		t.Error("Expected ErrUnauthorized but got", err)
+	}
+}
@@ -0,0 +1,171 @@
+// Package schedule selects daily content, such as a fact of the day or an
+// advent calendar, by the date where the user's device is. The time zone is
+// read from the Alexa Settings API, falling back to a default for the locale.
+package schedule
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"time"
+
+	// The Lambda provided runtimes have no zoneinfo database.
+	_ "time/tzdata"
+
+	alexa "github.com/ericdaugherty/alexa-skills-kit-golang"
+	"github.com/ericdaugherty/alexa-skills-kit-golang/internal/dates"
+)
+
+const dateLayout = "2006-01-02"
+const yearlyLayout = "01-02"
+
+// ErrNoContent reports that no entry is scheduled for a date.
+var ErrNoContent = errors.New("schedule: no content for date")
+
+// Entry is a single item of content. Date is either a date, 2006-12-25, for
+// that day only, or a month and day, 12-25, for that day every year. Rotation
+// entries have no Date.
+type Entry struct {
+	ID    string            `json:"id"`
+	Date  string            `json:"date,omitempty"`
+	Title string            `json:"title,omitempty"`
+	Text  string            `json:"text"`
+	Data  map[string]string `json:"data,omitempty"`
+}
+
+// Content is a dated content file. For each date, an entry for that date is
+// used first, then an entry for that day every year, and then the rotation,
+// which cycles one entry a day from RotationStart, or from January 1 of the
+// year 1 if it is empty.
+type Content struct {
+	Entries       []Entry `json:"entries"`
+	Rotation      []Entry `json:"rotation,omitempty"`
+	RotationStart string  `json:"rotationStart,omitempty"`
+}
+
+// ReadContent reads Content from a JSON file.
+func ReadContent(path string) (*Content, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c := &Content{}
+	if err := json.Unmarshal(b, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// EntryFor returns the entry for the calendar date of date.
+func (c *Content) EntryFor(date time.Time) (*Entry, error) {
+	day := date.Format(dateLayout)
+	for i := range c.Entries {
+		if c.Entries[i].Date == day {
+			return &c.Entries[i], nil
+		}
+	}
+	yearly := date.Format(yearlyLayout)
+	for i := range c.Entries {
+		if c.Entries[i].Date == yearly {
+			return &c.Entries[i], nil
+		}
+	}
+	if len(c.Rotation) == 0 {
+		return nil, ErrNoContent
+	}
+
+	var start time.Time
+	if c.RotationStart != "" {
+		var err error
+		if start, err = time.Parse(dateLayout, c.RotationStart); err != nil {
+			return nil, err
+		}
+	}
+	days := dates.DaysBetween(start, date)
+	if days < 0 {
+		return nil, ErrNoContent
+	}
+	return &c.Rotation[days%len(c.Rotation)], nil
+}
+
+// Scheduler selects the entry of Content for the device-local date of a
+// request. Now is used as the current time, for previewing other dates in
+// tests.
+type Scheduler struct {
+	Content *Content
+	Now     func() time.Time
+}
+
+// NewScheduler creates a Scheduler for content.
+func NewScheduler(content *Content) *Scheduler {
+	return &Scheduler{Content: content}
+}
+
+// Today returns the entry for the device-local date of the request in ctx.
+func (s *Scheduler) Today(ctx context.Context) (*Entry, error) {
+	return s.Content.EntryFor(s.LocalNow(ctx))
+}
+
+// LocalNow returns the current time in the time zone of the device of the
+// request in ctx.
+func (s *Scheduler) LocalNow(ctx context.Context) time.Time {
+	now := time.Now
+	if s.Now != nil {
+		now = s.Now
+	}
+	return now().In(s.Location(ctx))
+}
+
+// Location returns the time zone of the device of the request in ctx, from
+// the Settings API. If it cannot be read, the default for the locale is
+// returned.
+func (s *Scheduler) Location(ctx context.Context) *time.Location {
+	requestEnv := alexa.RequestEnvelopeFromContext(ctx)
+	if requestEnv == nil || requestEnv.Context == nil {
+		return DefaultLocation(alexa.LocaleFromContext(ctx))
+	}
+
+	deviceID := requestEnv.Context.System.Device.DeviceID
+	if client := alexa.ServiceClientFromContext(ctx); client != nil && client.Endpoint != "" && deviceID != "" {
+		loc, err := client.TimeZone(ctx, deviceID)
+		if err == nil {
+			return loc
+		}
+		alexa.LoggerFromContext(ctx).Println("Error reading device time zone.", err.Error())
+	}
+	return DefaultLocation(alexa.LocaleFromContext(ctx))
+}
+
+// localeZones are the time zones used for a locale when the device time zone
+// is unknown. Locales spanning several zones use the most populous.
+var localeZones = map[string]string{
+	"ar-SA": "Asia/Riyadh",
+	"de-DE": "Europe/Berlin",
+	"en-AU": "Australia/Sydney",
+	"en-CA": "America/Toronto",
+	"en-GB": "Europe/London",
+	"en-IE": "Europe/Dublin",
+	"en-IN": "Asia/Kolkata",
+	"en-US": "America/New_York",
+	"es-ES": "Europe/Madrid",
+	"es-MX": "America/Mexico_City",
+	"es-US": "America/New_York",
+	"fr-CA": "America/Toronto",
+	"fr-FR": "Europe/Paris",
+	"hi-IN": "Asia/Kolkata",
+	"it-IT": "Europe/Rome",
+	"ja-JP": "Asia/Tokyo",
+	"nl-NL": "Europe/Amsterdam",
+	"pt-BR": "America/Sao_Paulo",
+}
+
+// DefaultLocation returns the default time zone for locale, or UTC.
+func DefaultLocation(locale string) *time.Location {
+	if name, ok := localeZones[locale]; ok {
+		if loc, err := time.LoadLocation(name); err == nil {
+			return loc
+		}
+	}
+	return time.UTC
+}
@@ -0,0 +1,153 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	alexa "github.com/ericdaugherty/alexa-skills-kit-golang"
+)
+
+const contentJSON = `{
+  "entries": [
+    {"id": "advent-1", "date": "12-01", "text": "Door one"},
+    {"id": "launch", "date": "2026-11-30", "text": "Launch day"}
+  ],
+  "rotation": [
+    {"id": "fact-a", "text": "Fact A"},
+    {"id": "fact-b", "text": "Fact B"},
+    {"id": "fact-c", "text": "Fact C"}
+  ],
+  "rotationStart": "2026-01-01"
+}`
+
+func readTestContent(t *testing.T) *Content {
+	path := filepath.Join(t.TempDir(), "content.json")
+	os.WriteFile(path, []byte(contentJSON), 0644)
+	c, err := ReadContent(path)
+	if err != nil {
+		t.Fatal("Error reading content. " + err.Error())
+	}
+	return c
+}
+
+func TestEntryFor(t *testing.T) {
+	c := readTestContent(t)
+	cases := map[string]string{
+		"2026-11-30": "launch",
+		"2026-12-01": "advent-1",
+		"2027-12-01": "advent-1",
+		"2026-01-01": "fact-a",
+		"2026-01-02": "fact-b",
+		"2026-01-04": "fact-a",
+	}
+	for day, id := range cases {
+		date, _ := time.Parse(dateLayout, day)
+		entry, err := c.EntryFor(date)
+		if err != nil || entry.ID != id {
+			t.Errorf("Expected %s on %s but was %v %v", id, day, entry, err)
+		}
+	}
+
+	date, _ := time.Parse(dateLayout, "2025-12-31")
+	if _, err := c.EntryFor(date); !errors.Is(err, ErrNoContent) {
+		t.Error("Expected ErrNoContent before the rotation starts but got", err)
+	}
+}
+
+type scheduleHandler struct {
+	scheduler *Scheduler
+	entry     *Entry
+}
+
+func (h *scheduleHandler) OnSessionStarted(context.Context, *alexa.Request, *alexa.Session, *alexa.Context, *alexa.Response) error {
+	return nil
+}
+
+func (h *scheduleHandler) OnLaunch(ctx context.Context, request *alexa.Request, session *alexa.Session, aContext *alexa.Context, response *alexa.Response) error {
+	var err error
+	h.entry, err = h.scheduler.Today(ctx)
+	return err
+}
+
+func (h *scheduleHandler) OnIntent(context.Context, *alexa.Request, *alexa.Session, *alexa.Context, *alexa.Response) error {
+	return nil
+}
+
+func (h *scheduleHandler) OnSessionEnded(context.Context, *alexa.Request, *alexa.Session, *alexa.Context, *alexa.Response) error {
+	return nil
+}
+
+// todayOnDevice returns the entry for a launch request from a device in zone,
+// or from a device whose time zone cannot be read if zone is empty.
+func todayOnDevice(t *testing.T, now time.Time, locale, zone string) *Entry {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if zone == "" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(`"` + zone + `"`))
+	}))
+	defer server.Close()
+
+	scheduler := NewScheduler(readTestContent(t))
+	scheduler.Now = func() time.Time { return now }
+	h := &scheduleHandler{scheduler: scheduler}
+	a := &alexa.Alexa{RequestHandler: h, IgnoreApplicationID: true, IgnoreTimestamp: true}
+	a.ServiceClientFactory = func(*alexa.Context) *alexa.ServiceClient {
+		return &alexa.ServiceClient{Endpoint: server.URL, MaxRetries: -1}
+	}
+
+	requestEnv := &alexa.RequestEnvelope{
+		Session: &alexa.Session{},
+		Request: &alexa.Request{Type: "LaunchRequest", Locale: locale},
+		Context: &alexa.Context{},
+	}
+	requestEnv.Context.System.Device.DeviceID = "device-1"
+	if _, err := a.ProcessRequest(context.Background(), requestEnv); err != nil {
+		t.Fatal("Error processing request. " + err.Error())
+	}
+	return h.entry
+}
+
+func TestEntryForWithoutRotationStart(t *testing.T) {
+	c := &Content{Rotation: []Entry{{ID: "a"}, {ID: "b"}, {ID: "c"}}}
+	day := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	seen := make(map[string]bool)
+	var previous string
+	for i := 0; i < 4; i++ {
+		entry, err := c.EntryFor(day.AddDate(0, 0, i))
+		if err != nil {
+			t.Fatal("Error selecting entry. " + err.Error())
+		}
+		if entry.ID == previous {
+			t.Errorf("Expected the rotation to change every day but was %s twice", entry.ID)
+		}
+		seen[entry.ID] = true
+		previous = entry.ID
+	}
+	if len(seen) != 3 {
+		t.Error("Expected every entry of the rotation but were", seen)
+	}
+}
+
+func TestSchedulerUsesDeviceDate(t *testing.T) {
+	// 06:30 UTC on December 1st is still November 30th in Los Angeles.
+	now := time.Date(2026, 12, 1, 6, 30, 0, 0, time.UTC)
+
+	if e := todayOnDevice(t, now, "en-US", "America/Los_Angeles"); e.ID != "launch" {
+		t.Error("Expected the Los Angeles date to be used but entry was", e.ID)
+	}
+	if e := todayOnDevice(t, now, "en-US", "Asia/Tokyo"); e.ID != "advent-1" {
+		t.Error("Expected the Tokyo date to be used but entry was", e.ID)
+	}
+	// Without the Settings API the en-US default, New York, is used.
+	if e := todayOnDevice(t, now, "en-US", ""); e.ID != "advent-1" {
+		t.Error("Expected the locale default time zone to be used but entry was", e.ID)
+	}
+}
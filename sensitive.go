@@ -0,0 +1,318 @@
+package alexa
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaskedValue replaces the values of sensitive slots in SDK output.
+const MaskedValue = "****"
+
+// SensitiveSlots identifies slots that carry personal data, such as phone
+// numbers, names or account numbers. When set on Alexa, their values are
+// masked in everything the SDK logs and in the copies of the request and
+// response passed to PostResponseHooks, which is where recording, analytics
+// and conversation history belong. The response sent to Alexa is unchanged.
+//
+// A nil *SensitiveSlots masks nothing.
+type SensitiveSlots struct {
+	names       map[string]bool
+	intentSlots map[string]bool
+}
+
+// NewSensitiveSlots marks slots as sensitive by slot name, in any intent, and
+// by slot type. Slot types are resolved to intent slots using models.
+func NewSensitiveSlots(names []string, types []string, models ...*InteractionModel) *SensitiveSlots {
+	s := &SensitiveSlots{names: make(map[string]bool), intentSlots: make(map[string]bool)}
+	for _, n := range names {
+		s.names[n] = true
+	}
+	sensitiveTypes := make(map[string]bool)
+	for _, t := range types {
+		sensitiveTypes[t] = true
+	}
+	for _, model := range models {
+		for _, intent := range model.InteractionModel.LanguageModel.Intents {
+			for _, slot := range intent.Slots {
+				if sensitiveTypes[slot.Type] {
+					s.intentSlots[intent.Name+"/"+slot.Name] = true
+				}
+			}
+		}
+	}
+	return s
+}
+
+// IsSensitive reports whether the slot of intent is sensitive.
+func (s *SensitiveSlots) IsSensitive(intent, slot string) bool {
+	if s == nil {
+		return false
+	}
+	return s.names[slot] || s.intentSlots[intent+"/"+slot]
+}
+
+// MaskIntent returns a copy of intent with the values of sensitive slots masked.
+func (s *SensitiveSlots) MaskIntent(intent Intent) Intent {
+	if s == nil || len(intent.Slots) == 0 {
+		return intent
+	}
+	slots := make(map[string]IntentSlot, len(intent.Slots))
+	for name, slot := range intent.Slots {
+		if s.IsSensitive(intent.Name, name) {
+			if slot.Value != "" {
+				slot.Value = MaskedValue
+			}
+			slot.Resolutions = nil
+			slot.SlotValue = nil
+		}
+		slots[name] = slot
+	}
+	intent.Slots = slots
+	return intent
+}
+
+// MaskText masks every occurrence in text of a value of a sensitive slot of
+// intent, for output such as log lines, speech or cards that may echo it.
+func (s *SensitiveSlots) MaskText(intent *Intent, text string) string {
+	return s.masker(intent).text(text)
+}
+
+// MaskRequest returns a copy of requestEnv with the values of sensitive slots
+// masked, including session attributes named after sensitive slots or holding
+// one of their values.
+func (s *SensitiveSlots) MaskRequest(requestEnv *RequestEnvelope) *RequestEnvelope {
+	if s == nil {
+		return requestEnv
+	}
+	return s.masker(requestIntent(requestEnv)).request(requestEnv)
+}
+
+// MaskResponse returns a copy of responseEnv with the values of the
+// sensitive slots of the request masked, including session attributes named
+// after sensitive slots or holding one of their values.
+func (s *SensitiveSlots) MaskResponse(requestEnv *RequestEnvelope, responseEnv *ResponseEnvelope) *ResponseEnvelope {
+	if s == nil {
+		return responseEnv
+	}
+	return s.masker(requestIntent(requestEnv)).response(responseEnv)
+}
+
+func requestIntent(requestEnv *RequestEnvelope) *Intent {
+	if requestEnv == nil || requestEnv.Request == nil {
+		return nil
+	}
+	return &requestEnv.Request.Intent
+}
+
+// masker masks the values of the sensitive slots of one request. Its pattern
+// is compiled once and used for all of the output of the request.
+type masker struct {
+	s  *SensitiveSlots
+	re *regexp.Regexp
+}
+
+func (s *SensitiveSlots) masker(intent *Intent) *masker {
+	return &masker{s: s, re: s.pattern(intent)}
+}
+
+func (m *masker) text(text string) string {
+	return maskString(m.re, text)
+}
+
+func (m *masker) request(requestEnv *RequestEnvelope) *RequestEnvelope {
+	if m.s == nil || requestEnv == nil || requestEnv.Request == nil {
+		return requestEnv
+	}
+	masked := &RequestEnvelope{}
+	if err := m.json(requestEnv, masked); err != nil {
+		return &RequestEnvelope{Version: requestEnv.Version}
+	}
+	if masked.Session != nil {
+		m.attributes(masked.Session.Attributes.String)
+	}
+	if masked.Request != nil {
+		masked.Request.Intent = m.s.MaskIntent(masked.Request.Intent)
+	}
+	return masked
+}
+
+func (m *masker) response(responseEnv *ResponseEnvelope) *ResponseEnvelope {
+	if m.s == nil || responseEnv == nil {
+		return responseEnv
+	}
+	masked := &ResponseEnvelope{}
+	if err := m.json(responseEnv, masked); err != nil {
+		return &ResponseEnvelope{Version: responseEnv.Version}
+	}
+	m.attributes(masked.SessionAttributes)
+	return masked
+}
+
+// attribute formats the value of a session attribute for output.
+func (m *masker) attribute(name string, value interface{}) string {
+	if m.isSensitiveAttribute(name, value) {
+		return MaskedValue
+	}
+	return m.text(fmt.Sprint(value))
+}
+
+// attributes masks whole the session attributes that are sensitive. Values
+// of sensitive slots within other attributes are masked by json.
+func (m *masker) attributes(attributes map[string]interface{}) {
+	for name, value := range attributes {
+		if m.isSensitiveAttribute(name, value) {
+			attributes[name] = MaskedValue
+		}
+	}
+}
+
+// isSensitiveAttribute reports whether a session attribute is named after a
+// sensitive slot, or its value, such as a number, is the value of one.
+func (m *masker) isSensitiveAttribute(name string, value interface{}) bool {
+	if m.s != nil && m.s.names[name] {
+		return true
+	}
+	if m.re == nil {
+		return false
+	}
+	if s, ok := value.(string); ok {
+		return m.text(s) == MaskedValue
+	}
+	b, err := json.Marshal(value)
+	return err == nil && m.text(string(b)) == MaskedValue
+}
+
+// json copies in to out through JSON, masking the values of the sensitive
+// slots in every string.
+func (m *masker) json(in interface{}, out interface{}) error {
+	b, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	b, err = json.Marshal(maskValue(v, m.re))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, out)
+}
+
+// pattern matches the raw values of the sensitive slots of intent.
+func (s *SensitiveSlots) pattern(intent *Intent) *regexp.Regexp {
+	if s == nil || intent == nil {
+		return nil
+	}
+
+	var values []string
+	add := func(v string) {
+		if strings.TrimSpace(v) != "" && v != MaskedValue {
+			values = append(values, regexp.QuoteMeta(v))
+		}
+	}
+	var addSlotValue func(v *IntentSlotValue)
+	addResolutions := func(r *Resolutions) {
+		if r == nil {
+			return
+		}
+		for _, a := range r.ResolutionsPerAuthority {
+			for _, v := range a.Values {
+				add(v.Value.Name)
+				add(v.Value.ID)
+			}
+		}
+	}
+	addSlotValue = func(v *IntentSlotValue) {
+		if v == nil {
+			return
+		}
+		add(v.Value)
+		addResolutions(v.Resolutions)
+		for _, child := range v.Values {
+			addSlotValue(child)
+		}
+	}
+	for name, slot := range intent.Slots {
+		if s.IsSensitive(intent.Name, name) {
+			add(slot.Value)
+			addResolutions(slot.Resolutions)
+			addSlotValue(slot.SlotValue)
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+
+	// Longer values first, so that a value containing another is masked whole.
+	// maskString only masks matches that are whole words, so that a short
+	// value such as "1" does not mask unrelated text.
+	sort.Slice(values, func(i, j int) bool { return len(values[i]) > len(values[j]) })
+	return regexp.MustCompile(`(?i)` + strings.Join(values, "|"))
+}
+
+// maskString masks the matches of re that are whole words. Word boundaries
+// are checked by hand, rather than in re, so that repeated values separated
+// by a single character are all masked.
+func maskString(re *regexp.Regexp, s string) string {
+	if re == nil {
+		return s
+	}
+	var b strings.Builder
+	done, pos := 0, 0
+	for pos <= len(s) {
+		loc := re.FindStringIndex(s[pos:])
+		if loc == nil {
+			break
+		}
+		start, end := pos+loc[0], pos+loc[1]
+		if end > start && isWordBoundary(s, start, end) {
+			b.WriteString(s[done:start])
+			b.WriteString(MaskedValue)
+			done, pos = end, end
+			continue
+		}
+		// Not a whole word: try again from the next character.
+		_, size := utf8.DecodeRuneInString(s[start:])
+		pos = start + max(size, 1)
+	}
+	b.WriteString(s[done:])
+	return b.String()
+}
+
+// isWordBoundary reports whether s[start:end] is not preceded or followed by
+// a letter or number.
+func isWordBoundary(s string, start, end int) bool {
+	if r, _ := utf8.DecodeLastRuneInString(s[:start]); start > 0 && (unicode.IsLetter(r) || unicode.IsNumber(r)) {
+		return false
+	}
+	if r, _ := utf8.DecodeRuneInString(s[end:]); end < len(s) && (unicode.IsLetter(r) || unicode.IsNumber(r)) {
+		return false
+	}
+	return true
+}
+
+func maskValue(v interface{}, re *regexp.Regexp) interface{} {
+	if re == nil {
+		return v
+	}
+	switch t := v.(type) {
+	case string:
+		return maskString(re, t)
+	case map[string]interface{}:
+		for k, child := range t {
+			t[k] = maskValue(child, re)
+		}
+	case []interface{}:
+		for i, child := range t {
+			t[i] = maskValue(child, re)
+		}
+	}
+	return v
+}
@@ -0,0 +1,219 @@
+package alexa
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+const rawPhone = "5551234567"
+const rawContact = "Ada Lovelace"
+
+type sensitiveHandler struct {
+	fail bool
+}
+
+func (h *sensitiveHandler) OnSessionStarted(context.Context, *Request, *Session, *Context, *Response) error {
+	return nil
+}
+
+func (h *sensitiveHandler) OnLaunch(context.Context, *Request, *Session, *Context, *Response) error {
+	return nil
+}
+
+func (h *sensitiveHandler) OnIntent(ctx context.Context, request *Request, session *Session, aContext *Context, response *Response) error {
+	phone := request.Intent.Slots["phone"].Value
+	contact := request.Intent.Slots["contact"].Value
+	if h.fail {
+		return errors.New("unable to call " + phone)
+	}
+	session.Attributes.String["contact"] = contact
+	session.Attributes.String["lastCall"] = map[string]interface{}{"number": phone}
+	response.SetOutputText("Calling " + contact + " at " + phone)
+	response.SetSimpleCard("Call", "Called "+phone)
+	return nil
+}
+
+func (h *sensitiveHandler) OnSessionEnded(context.Context, *Request, *Session, *Context, *Response) error {
+	return nil
+}
+
+func createCallRequest() *RequestEnvelope {
+	request := createRecipeRequest()
+	request.Request.Intent = Intent{Name: "CallIntent", Slots: map[string]IntentSlot{
+		"phone":   {Name: "phone", Value: rawPhone},
+		"contact": {Name: "contact", Value: rawContact},
+		"when":    {Name: "when", Value: "today"},
+	}}
+	return request
+}
+
+func callSensitiveSlots() *SensitiveSlots {
+	model := &InteractionModel{}
+	model.InteractionModel.LanguageModel.Intents = []ModelIntent{
+		{Name: "CallIntent", Slots: []ModelSlot{{Name: "phone", Type: "AMAZON.PhoneNumber"}, {Name: "when", Type: "AMAZON.DATE"}}},
+	}
+	return NewSensitiveSlots([]string{"contact"}, []string{"AMAZON.PhoneNumber"}, model)
+}
+
+// captureOutput returns everything written to the log and stdout by f.
+func captureOutput(t *testing.T, f func()) string {
+	var logBuf bytes.Buffer
+	log.SetOutput(&logBuf)
+	defer log.SetOutput(os.Stderr)
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	f()
+	os.Stdout = stdout
+	w.Close()
+	out, _ := io.ReadAll(r)
+
+	return logBuf.String() + string(out)
+}
+
+func assertMasked(t *testing.T, where, s string) {
+	t.Helper()
+	if strings.Contains(s, rawPhone) || strings.Contains(s, rawContact) {
+		t.Errorf("Expected sensitive values to be masked in %s but found them in %s", where, s)
+	}
+}
+
+func TestSensitiveSlotsIsSensitive(t *testing.T) {
+	s := callSensitiveSlots()
+	if !s.IsSensitive("CallIntent", "phone") {
+		t.Error("Expected phone to be sensitive by type.")
+	}
+	if !s.IsSensitive("OtherIntent", "contact") {
+		t.Error("Expected contact to be sensitive by name in every intent.")
+	}
+	if s.IsSensitive("CallIntent", "when") || s.IsSensitive("OtherIntent", "phone") {
+		t.Error("Expected slots not marked sensitive to be output.")
+	}
+	var none *SensitiveSlots
+	if none.IsSensitive("CallIntent", "phone") {
+		t.Error("Expected nil SensitiveSlots to mask nothing.")
+	}
+}
+
+func TestSensitiveSlotsMaskedInLogs(t *testing.T) {
+	alexa := getAlexaWithHandler(&sensitiveHandler{})
+	alexa.SensitiveSlots = callSensitiveSlots()
+
+	var responseEnv *ResponseEnvelope
+	out := captureOutput(t, func() {
+		var err error
+		responseEnv, err = alexa.ProcessRequest(context.Background(), createCallRequest())
+		if err != nil {
+			t.Error("Error processing request. " + err.Error())
+		}
+	})
+	assertMasked(t, "log output", out)
+	if !strings.Contains(out, "Setting  contact to "+MaskedValue) {
+		t.Error("Expected session attribute to be logged masked but output was", out)
+	}
+
+	// The response sent to Alexa is unchanged.
+	if responseEnv.Response.OutputSpeech.Text != "Calling "+rawContact+" at "+rawPhone {
+		t.Error("Expected response speech to be unmasked but was", responseEnv.Response.OutputSpeech.Text)
+	}
+
+	alexa = getAlexaWithHandler(&sensitiveHandler{fail: true})
+	alexa.SensitiveSlots = callSensitiveSlots()
+	out = captureOutput(t, func() {
+		alexa.ProcessRequest(context.Background(), createCallRequest())
+	})
+	assertMasked(t, "error log", out)
+	if !strings.Contains(out, "unable to call "+MaskedValue) {
+		t.Error("Expected error to be logged masked but output was", out)
+	}
+}
+
+func TestSensitiveSlotsMaskedInHooks(t *testing.T) {
+	alexa := getAlexaWithHandler(&sensitiveHandler{})
+	alexa.SensitiveSlots = callSensitiveSlots()
+
+	var hookRequest, hookResponse []byte
+	alexa.PostResponseHooks = []PostResponseHook{
+		func(ctx context.Context, requestEnv *RequestEnvelope, responseEnv *ResponseEnvelope) error {
+			hookRequest, _ = json.Marshal(requestEnv)
+			hookResponse, _ = json.Marshal(responseEnv)
+			return nil
+		},
+	}
+
+	request := createCallRequest()
+	request.Session.Attributes.String = map[string]interface{}{"contact": "Charles Babbage"}
+	captureOutput(t, func() {
+		alexa.ProcessRequest(context.Background(), request)
+	})
+
+	assertMasked(t, "hook request", string(hookRequest))
+	assertMasked(t, "hook response", string(hookResponse))
+	if strings.Contains(string(hookRequest), "Charles Babbage") {
+		t.Error("Expected session attributes named after sensitive slots to be masked but request was", string(hookRequest))
+	}
+	if !strings.Contains(string(hookRequest), `"value":"today"`) {
+		t.Error("Expected slots not marked sensitive to be kept but request was", string(hookRequest))
+	}
+	if request.Request.Intent.Slots["phone"].Value != rawPhone {
+		t.Error("Expected the original request not to be modified.")
+	}
+}
+
+func TestSensitiveSlotsMaskAttributesByValue(t *testing.T) {
+	s := callSensitiveSlots()
+	request := createCallRequest()
+	request.Session.Attributes.String = map[string]interface{}{
+		"number":  5551234567,
+		"callee":  rawContact,
+		"counter": 3,
+	}
+
+	masked := s.MaskRequest(request)
+	attributes := masked.Session.Attributes.String
+	if attributes["number"] != MaskedValue || attributes["callee"] != MaskedValue {
+		t.Error("Expected attributes holding sensitive values to be masked but were", attributes)
+	}
+	if attributes["counter"] != float64(3) {
+		t.Error("Expected other attributes to be kept but were", attributes)
+	}
+}
+
+func TestSensitiveSlotsMaskText(t *testing.T) {
+	s := callSensitiveSlots()
+	intent := createCallRequest().Request.Intent
+	intent.Slots["phone"] = IntentSlot{Name: "phone", Value: "1"}
+
+	out := s.MaskText(&intent, "Dial 1 now, not 21 or 100")
+	if out != "Dial **** now, not 21 or 100" {
+		t.Error("Expected only whole values to be masked but was", out)
+	}
+}
+
+func TestSensitiveSlotsMaskRepeatedValues(t *testing.T) {
+	s := NewSensitiveSlots([]string{"pin"}, nil)
+	intent := Intent{Name: "VerifyIntent", Slots: map[string]IntentSlot{"pin": {Name: "pin", Value: "1234"}}}
+
+	for text, expected := range map[string]string{
+		"pin 1234 1234,1234":  "pin **** ****,****",
+		"1234,1234":           "****,****",
+		"11234 1234x 1234":    "11234 1234x ****",
+		"codes: 1234/1234.":   "codes: ****/****.",
+		"ééé 1234é 1234 1234": "ééé 1234é **** ****",
+	} {
+		if out := s.MaskText(&intent, text); out != expected {
+			t.Errorf("Expected %q to be masked as %q but was %q", text, expected, out)
+		}
+	}
+}
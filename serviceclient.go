@@ -0,0 +1,250 @@
+package alexa
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultMaxRetries = 3
+const defaultMinBackoff = 100 * time.Millisecond
+const defaultMaxBackoff = 2 * time.Second
+
+// ErrUnauthorized reports that an Alexa API rejected the access token (HTTP 401).
+var ErrUnauthorized = errors.New("alexa api: unauthorized")
+
+// ErrForbidden reports that the skill or user lacks permission for an Alexa API (HTTP 403).
+var ErrForbidden = errors.New("alexa api: forbidden")
+
+// ErrThrottled reports that an Alexa API throttled the request (HTTP 429).
+var ErrThrottled = errors.New("alexa api: too many requests")
+
+// ErrServiceUnavailable reports that an Alexa API failed with a server error (HTTP 5xx).
+var ErrServiceUnavailable = errors.New("alexa api: service unavailable")
+
+// APIError contains the details of a failed call to an Alexa API, parsed from
+// the error body returned by Alexa. Use errors.Is with ErrUnauthorized,
+// ErrForbidden, ErrThrottled or ErrServiceUnavailable to classify it.
+type APIError struct {
+	StatusCode int
+	Type       string
+	Message    string
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	msg := "alexa api: " + strconv.Itoa(e.StatusCode) + " " + http.StatusText(e.StatusCode)
+	if e.Type != "" {
+		msg += " (" + e.Type + ")"
+	}
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+// Unwrap returns the sentinel error matching the status code, if any.
+func (e *APIError) Unwrap() error {
+	switch {
+	case e.StatusCode == http.StatusUnauthorized:
+		return ErrUnauthorized
+	case e.StatusCode == http.StatusForbidden:
+		return ErrForbidden
+	case e.StatusCode == http.StatusTooManyRequests:
+		return ErrThrottled
+	case e.StatusCode >= 500:
+		return ErrServiceUnavailable
+	}
+	return nil
+}
+
+// Temporary reports whether the call may succeed if retried.
+func (e *APIError) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// ServiceClient is the shared transport used to call the Alexa APIs found at
+// Context.System.APIEndpoint. Failed calls are returned as *APIError, and
+// idempotent calls are retried with jittered backoff on throttling and server
+// errors. Retries honor the Retry-After header and never wait past the
+// deadline of the context passed to Do.
+type ServiceClient struct {
+	Endpoint    string
+	AccessToken string
+	HTTPClient  *http.Client
+
+	// MaxRetries is the number of retries after the first attempt. Zero uses
+	// the default of 3, a negative value disables retries.
+	MaxRetries int
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+// NewServiceClient creates a ServiceClient for the API endpoint and access
+// token sent with the request.
+func NewServiceClient(context *Context) *ServiceClient {
+	return &ServiceClient{
+		Endpoint:    context.System.APIEndpoint,
+		AccessToken: context.System.APIAccessToken,
+	}
+}
+
+// Do sends a request to the Alexa API at path. If body is not nil it is sent
+// as JSON, and if result is not nil a successful JSON response is decoded into it.
+func (c *ServiceClient) Do(ctx context.Context, method, path string, body interface{}, result interface{}) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		if err != nil {
+			return err
+		}
+	}
+
+	retries := c.MaxRetries
+	if retries == 0 {
+		retries = defaultMaxRetries
+	}
+	if !isIdempotent(method) {
+		retries = 0
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := c.send(ctx, method, path, payload, result)
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		var wait time.Duration
+		var apiErr *APIError
+		if errors.As(err, &apiErr) {
+			if !apiErr.Temporary() {
+				return err
+			}
+			wait = apiErr.RetryAfter
+		}
+		if attempt >= retries {
+			return err
+		}
+		if wait == 0 {
+			wait = c.backoff(attempt)
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Now().Add(wait).After(deadline) {
+			return err
+		}
+
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return err
+		case <-t.C:
+		}
+	}
+}
+
+func (c *ServiceClient) send(ctx context.Context, method, path string, payload []byte, result interface{}) error {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(c.Endpoint, "/")+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.AccessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.AccessToken)
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return parseAPIError(resp, b)
+	}
+	if result == nil || len(b) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(b, result); err != nil {
+		return fmt.Errorf("alexa api: unable to decode response: %w", err)
+	}
+	return nil
+}
+
+// backoff returns the jittered wait before the retry following attempt.
+func (c *ServiceClient) backoff(attempt int) time.Duration {
+	min := c.MinBackoff
+	if min <= 0 {
+		min = defaultMinBackoff
+	}
+	max := c.MaxBackoff
+	if max <= 0 {
+		max = defaultMaxBackoff
+	}
+	d := min << uint(attempt)
+	if d > max || d <= 0 {
+		d = max
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+func parseAPIError(resp *http.Response, body []byte) *APIError {
+	apiErr := &APIError{StatusCode: resp.StatusCode}
+
+	var errBody struct {
+		Type    string `json:"type"`
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(body, &errBody) == nil {
+		apiErr.Type = errBody.Type
+		if apiErr.Type == "" {
+			apiErr.Type = errBody.Code
+		}
+		apiErr.Message = errBody.Message
+	}
+
+	if v := resp.Header.Get("Retry-After"); v != "" {
+		if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+			apiErr.RetryAfter = time.Duration(secs) * time.Second
+		} else if t, err := http.ParseTime(v); err == nil && t.After(time.Now()) {
+			apiErr.RetryAfter = time.Until(t)
+		}
+	}
+
+	return apiErr
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	}
+	return false
+}
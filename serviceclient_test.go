@@ -0,0 +1,238 @@
+package alexa
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// scriptedResponse is a single canned reply from the fake Alexa API.
+type scriptedResponse struct {
+	Status     int
+	Body       string
+	RetryAfter string
+}
+
+// fakeAlexaAPI replies to each call with the next scripted response, and with
+// 200 once the script is exhausted.
+type fakeAlexaAPI struct {
+	mu     sync.Mutex
+	script []scriptedResponse
+	calls  int
+	auth   string
+}
+
+func (f *fakeAlexaAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	f.auth = r.Header.Get("Authorization")
+
+	if len(f.script) == 0 {
+		w.Write([]byte(`{"value":"ok"}`))
+		return
+	}
+	next := f.script[0]
+	f.script = f.script[1:]
+	if next.RetryAfter != "" {
+		w.Header().Set("Retry-After", next.RetryAfter)
+	}
+	w.WriteHeader(next.Status)
+	w.Write([]byte(next.Body))
+}
+
+func newTestServiceClient(f *fakeAlexaAPI) (*ServiceClient, func()) {
+	server := httptest.NewServer(f)
+	client := &ServiceClient{
+		Endpoint:    server.URL,
+		AccessToken: "token123",
+		MinBackoff:  time.Millisecond,
+		MaxBackoff:  5 * time.Millisecond,
+	}
+	return client, server.Close
+}
+
+func TestServiceClientSuccess(t *testing.T) {
+	f := &fakeAlexaAPI{}
+	client, done := newTestServiceClient(f)
+	defer done()
+
+	var result struct {
+		Value string `json:"value"`
+	}
+	err := client.Do(context.Background(), http.MethodGet, "/v1/test", nil, &result)
+	if err != nil {
+		t.Fatal("Expected call to succeed but got error", err)
+	}
+	if result.Value != "ok" {
+		t.Error("Expected result value to be ok but was", result.Value)
+	}
+	if f.auth != "Bearer token123" {
+		t.Error("Expected Authorization header to be 'Bearer token123' but was", f.auth)
+	}
+}
+
+func TestServiceClientRetriesServerErrors(t *testing.T) {
+	f := &fakeAlexaAPI{script: []scriptedResponse{
+		{Status: 503, Body: `{"type":"SERVICE_UNAVAILABLE","message":"try again"}`},
+		{Status: 429, Body: `{"type":"TOO_MANY_REQUESTS"}`},
+	}}
+	client, done := newTestServiceClient(f)
+	defer done()
+
+	err := client.Do(context.Background(), http.MethodGet, "/v1/test", nil, nil)
+	if err != nil {
+		t.Error("Expected call to succeed after retries but got error", err)
+	}
+	if f.calls != 3 {
+		t.Errorf("Expected 3 calls but there were %d", f.calls)
+	}
+}
+
+func TestServiceClientGivesUpAfterMaxRetries(t *testing.T) {
+	f := &fakeAlexaAPI{script: []scriptedResponse{
+		{Status: 500}, {Status: 500}, {Status: 500},
+	}}
+	client, done := newTestServiceClient(f)
+	defer done()
+	client.MaxRetries = 2
+
+	err := client.Do(context.Background(), http.MethodGet, "/v1/test", nil, nil)
+	if !errors.Is(err, ErrServiceUnavailable) {
+		t.Error("Expected ErrServiceUnavailable but got", err)
+	}
+	if f.calls != 3 {
+		t.Errorf("Expected 3 calls but there were %d", f.calls)
+	}
+}
+
+func TestServiceClientDoesNotRetryPost(t *testing.T) {
+	f := &fakeAlexaAPI{script: []scriptedResponse{{Status: 503}}}
+	client, done := newTestServiceClient(f)
+	defer done()
+
+	err := client.Do(context.Background(), http.MethodPost, "/v1/test", map[string]string{"a": "b"}, nil)
+	if !errors.Is(err, ErrServiceUnavailable) {
+		t.Error("Expected ErrServiceUnavailable but got", err)
+	}
+	if f.calls != 1 {
+		t.Errorf("Expected 1 call but there were %d", f.calls)
+	}
+}
+
+func TestServiceClientTypedErrors(t *testing.T) {
+	f := &fakeAlexaAPI{script: []scriptedResponse{
+		{Status: 401, Body: `{"type":"INVALID_ACCESS_TOKEN","message":"token expired"}`},
+		{Status: 403, Body: `{"code":"ACCESS_DENIED","message":"no consent"}`},
+	}}
+	client, done := newTestServiceClient(f)
+	defer done()
+	ctx := context.Background()
+
+	err := client.Do(ctx, http.MethodGet, "/v1/test", nil, nil)
+	if !errors.Is(err, ErrUnauthorized) {
+		t.Error("Expected ErrUnauthorized but got", err)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatal("Expected an *APIError but got", err)
+	}
+	if apiErr.Type != "INVALID_ACCESS_TOKEN" || apiErr.Message != "token expired" {
+		t.Errorf("Expected error body to be parsed but got type %s message %s", apiErr.Type, apiErr.Message)
+	}
+
+	err = client.Do(ctx, http.MethodGet, "/v1/test", nil, nil)
+	if !errors.Is(err, ErrForbidden) {
+		t.Error("Expected ErrForbidden but got", err)
+	}
+	if errors.As(err, &apiErr) && apiErr.Type != "ACCESS_DENIED" {
+		t.Error("Expected error code to be parsed into Type but was", apiErr.Type)
+	}
+	if f.calls != 2 {
+		t.Errorf("Expected 4xx errors not to be retried but there were %d calls", f.calls)
+	}
+}
+
+func TestServiceClientHonorsRetryAfter(t *testing.T) {
+	f := &fakeAlexaAPI{script: []scriptedResponse{{Status: 429, RetryAfter: "1"}}}
+	client, done := newTestServiceClient(f)
+	defer done()
+
+	start := time.Now()
+	err := client.Do(context.Background(), http.MethodGet, "/v1/test", nil, nil)
+	if err != nil {
+		t.Error("Expected call to succeed after retry but got error", err)
+	}
+	if time.Since(start) < time.Second {
+		t.Error("Expected retry to wait for Retry-After but it waited", time.Since(start))
+	}
+}
+
+func TestServiceClientRespectsDeadline(t *testing.T) {
+	f := &fakeAlexaAPI{script: []scriptedResponse{{Status: 429, RetryAfter: "5"}}}
+	client, done := newTestServiceClient(f)
+	defer done()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := client.Do(ctx, http.MethodGet, "/v1/test", nil, nil)
+	if !errors.Is(err, ErrThrottled) {
+		t.Error("Expected ErrThrottled but got", err)
+	}
+	if time.Since(start) > 400*time.Millisecond {
+		t.Error("Expected retry past the deadline to be skipped but call took", time.Since(start))
+	}
+	if f.calls != 1 {
+		t.Errorf("Expected 1 call but there were %d", f.calls)
+	}
+}
+
+func TestServiceClientTimeZone(t *testing.T) {
+	f := &fakeAlexaAPI{script: []scriptedResponse{{Status: http.StatusOK, Body: `"America/Los_Angeles"`}}}
+	client, done := newTestServiceClient(f)
+	defer done()
+
+	loc, err := client.TimeZone(context.Background(), "device-1")
+	if err != nil {
+		t.Fatal("Expected call to succeed but got error", err)
+	}
+	if loc.String() != "America/Los_Angeles" {
+		t.Error("Expected America/Los_Angeles but was", loc)
+	}
+}
+
+func TestServiceClientProactiveEvent(t *testing.T) {
+	var path string
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		json.NewDecoder(r.Body).Decode(&body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+	client := &ServiceClient{Endpoint: server.URL, AccessToken: "token123"}
+
+	event := &ProactiveEvent{ReferenceID: "ref-1"}
+	event.Event.Name = "AMAZON.MessageAlert.Activated"
+	event.RelevantAudience.Type = "Unicast"
+	if err := client.SendProactiveEvent(context.Background(), event, false); err != nil {
+		t.Fatal("Expected call to succeed but got error", err)
+	}
+	if path != "/v1/proactiveEvents/stages/development" || body["referenceId"] != "ref-1" {
+		t.Error("Expected event to be sent to the development stage but path was", path)
+	}
+
+	if err := client.SendSkillMessage(context.Background(), "amzn1.ask.account.1", map[string]string{"task": "1"}, time.Hour); err != nil {
+		t.Fatal("Expected call to succeed but got error", err)
+	}
+	if path != "/v1/skillmessages/users/amzn1.ask.account.1" || body["expiresAfterSeconds"] != float64(3600) {
+		t.Error("Expected a skill message expiring after an hour but body was", body)
+	}
+}
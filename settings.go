@@ -0,0 +1,21 @@
+package alexa
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// TimeZone returns the time zone set on the device by the user, from the
+// Alexa Settings API. The provided Lambda runtimes have no zoneinfo database,
+// so applications deployed there must import time/tzdata, as the schedule
+// package does.
+func (c *ServiceClient) TimeZone(ctx context.Context, deviceID string) (*time.Location, error) {
+	var name string
+	err := c.Do(ctx, http.MethodGet, "/v2/devices/"+url.PathEscape(deviceID)+"/settings/System.timeZone", nil, &name)
+	if err != nil {
+		return nil, err
+	}
+	return time.LoadLocation(name)
+}
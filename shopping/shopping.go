@@ -0,0 +1,152 @@
+// Package shopping starts Alexa Shopping Actions, which add Amazon products
+// to the user's cart or buy them, and reads their results.
+//
+// Tasks are started with Connections.StartConnection. When a task completes
+// Alexa resumes the session with a SessionResumedRequest, which a skill
+// handles by implementing alexa.SessionResumedHandler, or a SessionResumed
+// method with an alexa.Dispatcher, and reads with ResultFromRequest.
+package shopping
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	alexa "github.com/ericdaugherty/alexa-skills-kit-golang"
+)
+
+// ErrNoResult reports that a request is not the result of a shopping task
+// started with this package.
+var ErrNoResult = errors.New("request is not a shopping task result")
+
+// Task is a shopping task.
+type Task string
+
+// Shopping tasks.
+const (
+	TaskAddToCart Task = "AMAZON.AddToShoppingCart"
+	TaskBuy       Task = "AMAZON.BuyShoppingProducts"
+)
+
+// URI returns the connection URI of the task.
+func (t Task) URI() string {
+	return "connection://" + string(t) + "/1"
+}
+
+// TaskForURI returns the Task with the connection uri.
+func TaskForURI(uri string) (Task, bool) {
+	for _, t := range []Task{TaskAddToCart, TaskBuy} {
+		if uri == t.URI() {
+			return t, true
+		}
+	}
+	return "", false
+}
+
+// Status is the status code of a completed task.
+type Status string
+
+// Status codes.
+const (
+	// StatusOK is a task that completed. The user may still have chosen not
+	// to buy.
+	StatusOK Status = "200"
+	// StatusBadRequest is a task with invalid input, such as an unknown ASIN.
+	StatusBadRequest Status = "400"
+	// StatusError is a task that failed. Code tells why.
+	StatusError Status = "500"
+)
+
+// Code tells why a task failed. Codes not listed are returned as they are.
+type Code string
+
+// Codes of failed tasks.
+const (
+	CodeRetryLater         Code = "AlexaShopping.RetryLaterError"
+	CodeProductUnavailable Code = "AlexaShopping.ProductUnavailableError"
+	CodeUnsupportedLocale  Code = "AlexaShopping.UnsupportedLocaleError"
+)
+
+// Product is an Amazon product, identified by its ASIN.
+type Product struct {
+	ASIN string `json:"asin"`
+}
+
+// Input is the input of a shopping task.
+type Input struct {
+	Products []Product `json:"products"`
+}
+
+// NewInput returns the Input for the products with asins.
+func NewInput(asins ...string) Input {
+	in := Input{Products: make([]Product, len(asins))}
+	for i, asin := range asins {
+		in.Products[i] = Product{ASIN: asin}
+	}
+	return in
+}
+
+// AddToCart adds a directive to r starting the task that adds the products
+// with asins to the user's Amazon cart. The result has token.
+func AddToCart(r *alexa.Response, token string, asins ...string) {
+	Start(r, TaskAddToCart, token, asins...)
+}
+
+// Buy adds a directive to r starting the task that asks the user to buy the
+// products with asins. The result has token.
+func Buy(r *alexa.Response, token string, asins ...string) {
+	Start(r, TaskBuy, token, asins...)
+}
+
+// Start adds a directive to r starting task for the products with asins. The
+// task is recorded in the token sent to Alexa, so ResultFromRequest can tell
+// which task completed.
+func Start(r *alexa.Response, task Task, token string, asins ...string) {
+	r.AddConnectionsStartConnection(task.URI(), NewInput(asins...), string(task)+"|"+token)
+}
+
+// Result is the result of a shopping task.
+type Result struct {
+	Task Task
+	// Token is the token the task was started with.
+	Token   string
+	Status  Status
+	Message string
+	// Code is set when Status is not StatusOK, if Alexa gave one.
+	Code Code
+}
+
+// OK reports whether the task completed.
+func (r *Result) OK() bool {
+	return r.Status == StatusOK
+}
+
+// ResultFromRequest returns the Result of a shopping task started by Start,
+// or ErrNoResult if request is not one.
+func ResultFromRequest(request *alexa.Request) (*Result, error) {
+	cause := request.Cause
+	if cause == nil {
+		return nil, ErrNoResult
+	}
+	task, token, ok := strings.Cut(cause.Token, "|")
+	if !ok || (Task(task) != TaskAddToCart && Task(task) != TaskBuy) {
+		return nil, ErrNoResult
+	}
+
+	r := &Result{
+		Task:    Task(task),
+		Token:   token,
+		Status:  Status(cause.Status.Code),
+		Message: cause.Status.Message,
+	}
+	if len(cause.Result) > 0 && string(cause.Result) != "null" {
+		var result struct {
+			Code string `json:"code"`
+		}
+		if err := json.Unmarshal(cause.Result, &result); err != nil {
+			return nil, err
+		}
+		r.Code = Code(result.Code)
+	}
+	return r, nil
+}
@@ -0,0 +1,108 @@
+package shopping_test
+
+import (
+	"context"
+	"testing"
+
+	alexa "github.com/ericdaugherty/alexa-skills-kit-golang"
+	"github.com/ericdaugherty/alexa-skills-kit-golang/shopping"
+	"github.com/ericdaugherty/alexa-skills-kit-golang/shopping/shoppingtest"
+)
+
+// recipeSkill adds the ingredients of a recipe to the cart.
+type recipeSkill struct {
+	alexa.BaseSkill
+	result *shopping.Result
+}
+
+func (s *recipeSkill) AddIngredientsIntent(in *alexa.HandlerInput) error {
+	shopping.AddToCart(in.Response, "pancakes", "B000FLOUR1", "B00000EGGS")
+	return nil
+}
+
+func (s *recipeSkill) SessionResumed(in *alexa.HandlerInput) error {
+	result, err := shopping.ResultFromRequest(in.Request)
+	if err != nil {
+		return err
+	}
+	s.result = result
+	if result.OK() {
+		in.Response.SetOutputText("The ingredients are in your cart.")
+	} else if result.Code == shopping.CodeProductUnavailable {
+		in.Response.SetOutputText("Some ingredients are unavailable.")
+	}
+	return nil
+}
+
+func addIngredients(t *testing.T, store *shoppingtest.Store) (*recipeSkill, string) {
+	skill := &recipeSkill{}
+	a := &alexa.Alexa{RequestHandler: alexa.NewDispatcher(skill), IgnoreApplicationID: true, IgnoreTimestamp: true}
+
+	requestEnv := &alexa.RequestEnvelope{
+		Session: &alexa.Session{New: true},
+		Request: &alexa.Request{Type: "IntentRequest", Locale: "en-US", Intent: alexa.Intent{Name: "AddIngredientsIntent"}},
+	}
+	requestEnv.Session.User.UserID = "amzn1.ask.account.1"
+	responseEnv, err := a.ProcessRequest(context.Background(), requestEnv)
+	if err != nil {
+		t.Fatal("Error processing request. " + err.Error())
+	}
+
+	resumed, err := store.Complete(requestEnv, responseEnv)
+	if err != nil {
+		t.Fatal("Error completing task. " + err.Error())
+	}
+	responseEnv, err = a.ProcessRequest(context.Background(), resumed)
+	if err != nil {
+		t.Fatal("Error processing SessionResumedRequest. " + err.Error())
+	}
+	if responseEnv.Response.OutputSpeech == nil {
+		return skill, ""
+	}
+	return skill, responseEnv.Response.OutputSpeech.Text
+}
+
+func TestAddToCart(t *testing.T) {
+	store := shoppingtest.NewStore("B000FLOUR1", "B00000EGGS")
+	skill, text := addIngredients(t, store)
+	if text != "The ingredients are in your cart." {
+		t.Error("Expected the ingredients to be added but speech was", text)
+	}
+	if skill.result.Task != shopping.TaskAddToCart || skill.result.Token != "pancakes" {
+		t.Error("Expected the result of the AddToShoppingCart task but was", skill.result)
+	}
+	if cart := store.Carts["amzn1.ask.account.1"]; len(cart) != 2 {
+		t.Error("Expected two products in the cart but was", cart)
+	}
+}
+
+func TestAddToCartUnavailable(t *testing.T) {
+	store := shoppingtest.NewStore("B000FLOUR1")
+	skill, text := addIngredients(t, store)
+	if text != "Some ingredients are unavailable." || skill.result.Status != shopping.StatusError {
+		t.Error("Expected an unavailable product but speech was", text)
+	}
+	if cart := store.Carts["amzn1.ask.account.1"]; len(cart) != 0 {
+		t.Error("Expected an empty cart but was", cart)
+	}
+}
+
+func TestResultFromRequest(t *testing.T) {
+	request := &alexa.Request{Type: "SessionResumedRequest", Cause: &alexa.Cause{Token: "other"}}
+	if _, err := shopping.ResultFromRequest(request); err != shopping.ErrNoResult {
+		t.Error("Expected ErrNoResult for another task but got", err)
+	}
+
+	response := &alexa.Response{}
+	shopping.Buy(response, "order-1", "B000FLOUR1")
+	d := response.Directives[0].(alexa.ConnectionsDirective)
+	if d.URI != "connection://AMAZON.BuyShoppingProducts/1" {
+		t.Error("Expected the BuyShoppingProducts URI but was", d.URI)
+	}
+	request.Cause.Token = d.Token
+	request.Cause.Status.Code = "200"
+	result, err := shopping.ResultFromRequest(request)
+	if err != nil || result.Task != shopping.TaskBuy || result.Token != "order-1" || !result.OK() {
+		t.Error("Expected a completed Buy task but was", result, err)
+	}
+}
@@ -0,0 +1,155 @@
+// Package shoppingtest completes shopping tasks offline, the way Alexa
+// would: it reads the Connections.StartConnection directive of a response,
+// updates a fake cart or order history, and returns the SessionResumedRequest
+// that continues the session.
+package shoppingtest
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"time"
+
+	alexa "github.com/ericdaugherty/alexa-skills-kit-golang"
+	"github.com/ericdaugherty/alexa-skills-kit-golang/shopping"
+)
+
+// Errors reported by Store.
+var (
+	ErrNoTask      = errors.New("shoppingtest: response does not start a shopping task")
+	ErrUnknownTask = errors.New("shoppingtest: unknown shopping task")
+	ErrNoSession   = errors.New("shoppingtest: request has no session")
+)
+
+var asinPattern = regexp.MustCompile(`^[A-Z0-9]{10}$`)
+
+// Store is a fake Amazon store.
+type Store struct {
+	// Available lists the ASINs that can be bought. Other well-formed ASINs
+	// are unavailable.
+	Available map[string]bool
+	// Fail, if set, makes tasks fail with this code.
+	Fail shopping.Code
+	// Carts holds the ASINs added to the cart of each user.
+	Carts map[string][]string
+	// Orders holds the ASINs bought by each user, one slice per order.
+	Orders map[string][][]string
+}
+
+// NewStore creates a Store selling the products with asins.
+func NewStore(asins ...string) *Store {
+	s := &Store{
+		Available: make(map[string]bool),
+		Carts:     make(map[string][]string),
+		Orders:    make(map[string][][]string),
+	}
+	for _, asin := range asins {
+		s.Available[asin] = true
+	}
+	return s
+}
+
+// Complete runs the shopping task started by responseEnv, the response to
+// requestEnv, and returns the SessionResumedRequest Alexa would send next.
+func (s *Store) Complete(requestEnv *alexa.RequestEnvelope, responseEnv *alexa.ResponseEnvelope) (*alexa.RequestEnvelope, error) {
+	if requestEnv.Session == nil {
+		return nil, ErrNoSession
+	}
+	d, err := startConnection(responseEnv)
+	if err != nil {
+		return nil, err
+	}
+	task, ok := shopping.TaskForURI(d.URI)
+	if !ok {
+		return nil, ErrUnknownTask
+	}
+	var input shopping.Input
+	if err := remarshal(d.Input, &input); err != nil {
+		return nil, err
+	}
+
+	cause := &alexa.Cause{Type: "ConnectionCompleted", Token: d.Token}
+	userID := requestEnv.Session.User.UserID
+	status, message, code := s.run(task, userID, input)
+	cause.Status.Code = string(status)
+	cause.Status.Message = message
+	if code != "" {
+		cause.Result, _ = json.Marshal(map[string]string{"code": string(code)})
+	}
+
+	session := *requestEnv.Session
+	session.New = false
+	session.Attributes.String = responseEnv.SessionAttributes
+	next := &alexa.RequestEnvelope{
+		Version: requestEnv.Version,
+		Session: &session,
+		Context: requestEnv.Context,
+		Request: &alexa.Request{
+			Type:      "SessionResumedRequest",
+			RequestID: "amzn1.echo-api.request.shoppingtest",
+			Timestamp: time.Now().UTC().Format(time.RFC3339),
+			Cause:     cause,
+		},
+	}
+	if requestEnv.Request != nil {
+		next.Request.Locale = requestEnv.Request.Locale
+	}
+	// Go through JSON, as Alexa would.
+	resumed := &alexa.RequestEnvelope{}
+	return resumed, remarshal(next, resumed)
+}
+
+func (s *Store) run(task shopping.Task, userID string, input shopping.Input) (shopping.Status, string, shopping.Code) {
+	if len(input.Products) == 0 {
+		return shopping.StatusBadRequest, "no products", ""
+	}
+	asins := make([]string, len(input.Products))
+	for i, p := range input.Products {
+		if !asinPattern.MatchString(p.ASIN) {
+			return shopping.StatusBadRequest, "invalid ASIN " + p.ASIN, ""
+		}
+		asins[i] = p.ASIN
+	}
+	if s.Fail != "" {
+		return shopping.StatusError, "task failed", s.Fail
+	}
+	for _, asin := range asins {
+		if !s.Available[asin] {
+			return shopping.StatusError, "product unavailable: " + asin, shopping.CodeProductUnavailable
+		}
+	}
+
+	switch task {
+	case shopping.TaskAddToCart:
+		s.Carts[userID] = append(s.Carts[userID], asins...)
+	case shopping.TaskBuy:
+		s.Orders[userID] = append(s.Orders[userID], asins)
+	}
+	return shopping.StatusOK, "OK", ""
+}
+
+// startConnection returns the Connections.StartConnection directive of
+// responseEnv.
+func startConnection(responseEnv *alexa.ResponseEnvelope) (*alexa.ConnectionsDirective, error) {
+	if responseEnv == nil || responseEnv.Response == nil {
+		return nil, ErrNoTask
+	}
+	for _, d := range responseEnv.Response.Directives {
+		c := &alexa.ConnectionsDirective{}
+		if err := remarshal(d, c); err != nil {
+			continue
+		}
+		if c.Type == "Connections.StartConnection" {
+			return c, nil
+		}
+	}
+	return nil, ErrNoTask
+}
+
+func remarshal(v interface{}, out interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, out)
+}
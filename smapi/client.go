@@ -0,0 +1,320 @@
+// Package smapi provides a client for the Alexa Skill Management API (SMAPI),
+// for use in pipelines that update skill manifests and interaction models and
+// run simulations. The smapitest package provides an offline fake of the API.
+package smapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+
+	alexa "github.com/ericdaugherty/alexa-skills-kit-golang"
+)
+
+// StageDevelopment and StageLive are the stages of a skill.
+const (
+	StageDevelopment = "development"
+	StageLive        = "live"
+)
+
+// Status values reported for builds, simulations and invocations.
+const (
+	StatusInProgress = "IN_PROGRESS"
+	StatusSucceeded  = "SUCCEEDED"
+	StatusSuccessful = "SUCCESSFUL"
+	StatusFailed     = "FAILED"
+)
+
+const defaultPollInterval = 2 * time.Second
+
+// ErrBuildFailed reports that an interaction model or manifest build failed.
+var ErrBuildFailed = errors.New("smapi: build failed")
+
+// ErrSimulationFailed reports that a simulation or invocation failed.
+var ErrSimulationFailed = errors.New("smapi: simulation failed")
+
+// TokenSource provides access tokens for SMAPI. *alexa.LWATokenSource
+// configured with a refresh token satisfies it.
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// Client calls the Skill Management API.
+type Client struct {
+	// Region provides the API endpoint. Default value is alexa.RegionNA.
+	Region     alexa.Region
+	Tokens     TokenSource
+	HTTPClient *http.Client
+
+	// PollInterval is the wait between status checks while waiting for a build
+	// or simulation. Default value is 2 seconds.
+	PollInterval time.Duration
+}
+
+// NewClient creates a Client for region, exchanging the refresh token of a
+// developer for access tokens with Login with Amazon in the same region.
+func NewClient(region alexa.Region, clientID, clientSecret, refreshToken string) *Client {
+	tokens := &alexa.LWATokenSource{ClientID: clientID, ClientSecret: clientSecret, RefreshToken: refreshToken, Region: region}
+	return &Client{Region: region, Tokens: tokens}
+}
+
+// BuildStatus contains the status of the last update to a skill resource.
+type BuildStatus struct {
+	Status string `json:"status"`
+	Errors []struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"errors,omitempty"`
+}
+
+// SkillStatus contains the build status of the manifest and of the
+// interaction model for each locale.
+type SkillStatus struct {
+	Manifest *struct {
+		LastUpdateRequest BuildStatus `json:"lastUpdateRequest"`
+	} `json:"manifest,omitempty"`
+	InteractionModel map[string]struct {
+		LastUpdateRequest BuildStatus `json:"lastUpdateRequest"`
+	} `json:"interactionModel,omitempty"`
+}
+
+// Simulation contains the state and result of an utterance simulation.
+type Simulation struct {
+	ID     string            `json:"id"`
+	Status string            `json:"status"`
+	Result *SimulationResult `json:"result,omitempty"`
+}
+
+// SimulationResult contains what Alexa said and the invocations of the skill
+// made during a simulation.
+type SimulationResult struct {
+	AlexaExecutionInfo struct {
+		AlexaResponses []AlexaResponse `json:"alexaResponses"`
+	} `json:"alexaExecutionInfo"`
+	SkillExecutionInfo struct {
+		Invocations []Invocation `json:"invocations"`
+	} `json:"skillExecutionInfo"`
+	Error *ExecutionError `json:"error,omitempty"`
+}
+
+// AlexaResponse contains one response rendered by Alexa during a simulation.
+type AlexaResponse struct {
+	Type    string `json:"type"`
+	Content struct {
+		Caption string `json:"caption"`
+	} `json:"content"`
+}
+
+// ExecutionError describes why a simulation or invocation failed.
+type ExecutionError struct {
+	Message string `json:"message"`
+}
+
+// Invocation contains the request sent to the skill and the response it
+// returned.
+type Invocation struct {
+	InvocationRequest struct {
+		Endpoint string                 `json:"endpoint"`
+		Body     *alexa.RequestEnvelope `json:"body"`
+	} `json:"invocationRequest"`
+	InvocationResponse struct {
+		Body *alexa.ResponseEnvelope `json:"body"`
+	} `json:"invocationResponse"`
+}
+
+// InvocationResult contains the result of invoking the skill directly.
+type InvocationResult struct {
+	Status string            `json:"status"`
+	Result *InvocationOutput `json:"result,omitempty"`
+}
+
+// InvocationOutput contains the invocation of the skill, or why it failed.
+type InvocationOutput struct {
+	SkillExecutionInfo Invocation      `json:"skillExecutionInfo"`
+	Error              *ExecutionError `json:"error,omitempty"`
+}
+
+// GetManifest returns the skill manifest for stage.
+func (c *Client) GetManifest(ctx context.Context, skillID, stage string) (*alexa.SkillManifest, error) {
+	manifest := &alexa.SkillManifest{}
+	err := c.do(ctx, http.MethodGet, skillPath(skillID, stage, "manifest"), nil, manifest)
+	if err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// UpdateManifest replaces the skill manifest for stage. The update builds
+// asynchronously, use WaitForManifestBuild to wait for it.
+func (c *Client) UpdateManifest(ctx context.Context, skillID, stage string, manifest *alexa.SkillManifest) error {
+	return c.do(ctx, http.MethodPut, skillPath(skillID, stage, "manifest"), manifest, nil)
+}
+
+// GetInteractionModel returns the interaction model for stage and locale.
+func (c *Client) GetInteractionModel(ctx context.Context, skillID, stage, locale string) (*alexa.InteractionModel, error) {
+	model := &alexa.InteractionModel{}
+	err := c.do(ctx, http.MethodGet, skillPath(skillID, stage, "interactionModel/locales/"+url.PathEscape(locale)), nil, model)
+	if err != nil {
+		return nil, err
+	}
+	return model, nil
+}
+
+// UpdateInteractionModel replaces the interaction model for stage and locale.
+// The model builds asynchronously, use WaitForModelBuild to wait for it.
+func (c *Client) UpdateInteractionModel(ctx context.Context, skillID, stage, locale string, model *alexa.InteractionModel) error {
+	return c.do(ctx, http.MethodPut, skillPath(skillID, stage, "interactionModel/locales/"+url.PathEscape(locale)), model, nil)
+}
+
+// GetStatus returns the build status of the skill.
+func (c *Client) GetStatus(ctx context.Context, skillID string) (*SkillStatus, error) {
+	status := &SkillStatus{}
+	err := c.do(ctx, http.MethodGet, "/v1/skills/"+url.PathEscape(skillID)+"/status", nil, status)
+	if err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// WaitForModelBuild polls the build status of the interaction model for
+// locale until it completes. It returns ErrBuildFailed if the build failed.
+func (c *Client) WaitForModelBuild(ctx context.Context, skillID, locale string) (*BuildStatus, error) {
+	return c.waitForBuild(ctx, skillID, func(s *SkillStatus) *BuildStatus {
+		if m, ok := s.InteractionModel[locale]; ok {
+			return &m.LastUpdateRequest
+		}
+		return nil
+	})
+}
+
+// WaitForManifestBuild polls the build status of the manifest until it
+// completes. It returns ErrBuildFailed if the build failed.
+func (c *Client) WaitForManifestBuild(ctx context.Context, skillID string) (*BuildStatus, error) {
+	return c.waitForBuild(ctx, skillID, func(s *SkillStatus) *BuildStatus {
+		if s.Manifest != nil {
+			return &s.Manifest.LastUpdateRequest
+		}
+		return nil
+	})
+}
+
+func (c *Client) waitForBuild(ctx context.Context, skillID string, get func(*SkillStatus) *BuildStatus) (*BuildStatus, error) {
+	for {
+		status, err := c.GetStatus(ctx, skillID)
+		if err != nil {
+			return nil, err
+		}
+		build := get(status)
+		if build != nil {
+			switch build.Status {
+			case StatusSucceeded:
+				return build, nil
+			case StatusFailed:
+				return build, ErrBuildFailed
+			}
+		}
+		if err := c.wait(ctx); err != nil {
+			return build, err
+		}
+	}
+}
+
+// SimulateUtterance simulates the user saying utterance to the skill on a
+// device with locale, and waits for the simulation to complete.
+func (c *Client) SimulateUtterance(ctx context.Context, skillID, stage, locale, utterance string) (*Simulation, error) {
+	var body struct {
+		Session struct {
+			Mode string `json:"mode"`
+		} `json:"session"`
+		Input struct {
+			Content string `json:"content"`
+		} `json:"input"`
+		Device struct {
+			Locale string `json:"locale"`
+		} `json:"device"`
+	}
+	body.Session.Mode = "DEFAULT"
+	body.Input.Content = utterance
+	body.Device.Locale = locale
+
+	sim := &Simulation{}
+	err := c.do(ctx, http.MethodPost, skillPathV2(skillID, stage, "simulations"), body, sim)
+	if err != nil {
+		return nil, err
+	}
+
+	for sim.Status == StatusInProgress {
+		if err := c.wait(ctx); err != nil {
+			return sim, err
+		}
+		err := c.do(ctx, http.MethodGet, skillPathV2(skillID, stage, "simulations/"+url.PathEscape(sim.ID)), nil, sim)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if sim.Status != StatusSuccessful {
+		return sim, ErrSimulationFailed
+	}
+	return sim, nil
+}
+
+// InvokeSkill sends requestEnv directly to the skill endpoint in region and
+// returns the response.
+func (c *Client) InvokeSkill(ctx context.Context, skillID, stage string, region alexa.Region, requestEnv *alexa.RequestEnvelope) (*InvocationResult, error) {
+	var body struct {
+		EndpointRegion string `json:"endpointRegion"`
+		SkillRequest   struct {
+			Body *alexa.RequestEnvelope `json:"body"`
+		} `json:"skillRequest"`
+	}
+	body.EndpointRegion = region.Name
+	body.SkillRequest.Body = requestEnv
+
+	result := &InvocationResult{}
+	err := c.do(ctx, http.MethodPost, skillPathV2(skillID, stage, "invocations"), body, result)
+	if err != nil {
+		return nil, err
+	}
+	if result.Status != StatusSuccessful {
+		return result, ErrSimulationFailed
+	}
+	return result, nil
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body interface{}, result interface{}) error {
+	token, err := c.Tokens.Token(ctx)
+	if err != nil {
+		return err
+	}
+	endpoint := c.Region.APIEndpoint
+	if endpoint == "" {
+		endpoint = alexa.RegionNA.APIEndpoint
+	}
+	client := &alexa.ServiceClient{Endpoint: endpoint, AccessToken: token, HTTPClient: c.HTTPClient}
+	return client.Do(ctx, method, path, body, result)
+}
+
+func (c *Client) wait(ctx context.Context) error {
+	interval := c.PollInterval
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	t := time.NewTimer(interval)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+func skillPath(skillID, stage, resource string) string {
+	return "/v1/skills/" + url.PathEscape(skillID) + "/stages/" + url.PathEscape(stage) + "/" + resource
+}
+
+func skillPathV2(skillID, stage, resource string) string {
+	return "/v2/skills/" + url.PathEscape(skillID) + "/stages/" + url.PathEscape(stage) + "/" + resource
+}
@@ -0,0 +1,201 @@
+package smapi_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	alexa "github.com/ericdaugherty/alexa-skills-kit-golang"
+	"github.com/ericdaugherty/alexa-skills-kit-golang/smapi"
+	"github.com/ericdaugherty/alexa-skills-kit-golang/smapi/smapitest"
+)
+
+const skillID = "amzn1.ask.skill.ABC123"
+
+type helloHandler struct{}
+
+func (h *helloHandler) OnSessionStarted(context.Context, *alexa.Request, *alexa.Session, *alexa.Context, *alexa.Response) error {
+	return nil
+}
+
+func (h *helloHandler) OnLaunch(ctx context.Context, request *alexa.Request, session *alexa.Session, aContext *alexa.Context, response *alexa.Response) error {
+	response.SetOutputText("Welcome")
+	return nil
+}
+
+func (h *helloHandler) OnIntent(ctx context.Context, request *alexa.Request, session *alexa.Session, aContext *alexa.Context, response *alexa.Response) error {
+	switch request.Intent.Name {
+	case "GreetIntent":
+		response.SetOutputSSML("<speak>Hello " + request.Intent.Slots["name"].Value + "</speak>")
+	default:
+		return errors.New("invalid intent")
+	}
+	return nil
+}
+
+func (h *helloHandler) OnSessionEnded(context.Context, *alexa.Request, *alexa.Session, *alexa.Context, *alexa.Response) error {
+	return nil
+}
+
+func newModel(invocationName string) *alexa.InteractionModel {
+	model := &alexa.InteractionModel{}
+	model.InteractionModel.LanguageModel.InvocationName = invocationName
+	model.InteractionModel.LanguageModel.Intents = []alexa.ModelIntent{
+		{Name: "AMAZON.StopIntent"},
+		{Name: "GreetIntent", Slots: []alexa.ModelSlot{{Name: "name", Type: "AMAZON.FirstName"}}, Samples: []string{"say hello to {name}", "greet {name}"}},
+	}
+	return model
+}
+
+func newFake() (*smapitest.Server, *smapi.Client) {
+	skill := &alexa.Alexa{ApplicationID: skillID, RequestHandler: &helloHandler{}}
+	fake := smapitest.NewServer(skillID, skill)
+	fake.BuildPolls = 2
+	return fake, fake.Client()
+}
+
+func TestInteractionModelUpdateAndBuild(t *testing.T) {
+	fake, client := newFake()
+	defer fake.Close()
+	ctx := context.Background()
+
+	err := client.UpdateInteractionModel(ctx, skillID, smapi.StageDevelopment, "en-US", newModel("hello world"))
+	if err != nil {
+		t.Fatal("Error updating interaction model. " + err.Error())
+	}
+	build, err := client.WaitForModelBuild(ctx, skillID, "en-US")
+	if err != nil {
+		t.Fatal("Error waiting for build. " + err.Error())
+	}
+	if build.Status != smapi.StatusSucceeded {
+		t.Error("Expected build to succeed but was", build.Status)
+	}
+
+	model, err := client.GetInteractionModel(ctx, skillID, smapi.StageDevelopment, "en-US")
+	if err != nil {
+		t.Fatal("Error getting interaction model. " + err.Error())
+	}
+	if model.Intent("GreetIntent") == nil {
+		t.Error("Expected stored interaction model to contain GreetIntent.")
+	}
+
+	_, err = client.GetInteractionModel(ctx, skillID, smapi.StageDevelopment, "de-DE")
+	if err == nil {
+		t.Error("Expected missing locale to return an error.")
+	}
+}
+
+func TestInteractionModelBuildFailure(t *testing.T) {
+	fake, client := newFake()
+	defer fake.Close()
+	ctx := context.Background()
+
+	client.UpdateInteractionModel(ctx, skillID, smapi.StageDevelopment, "en-US", newModel(""))
+	build, err := client.WaitForModelBuild(ctx, skillID, "en-US")
+	if !errors.Is(err, smapi.ErrBuildFailed) {
+		t.Fatal("Expected ErrBuildFailed but got", err)
+	}
+	if len(build.Errors) != 1 {
+		t.Errorf("Expected 1 build error but there were %d", len(build.Errors))
+	}
+}
+
+func TestManifestUpdate(t *testing.T) {
+	fake, client := newFake()
+	defer fake.Close()
+	ctx := context.Background()
+
+	manifest := &alexa.SkillManifest{}
+	manifest.Manifest.ManifestVersion = "1.0"
+	manifest.Manifest.PublishingInformation = &alexa.PublishingInformation{
+		Locales: map[string]alexa.LocaleInformation{"en-US": {Name: "HelloWorld"}},
+	}
+	if err := client.UpdateManifest(ctx, skillID, smapi.StageDevelopment, manifest); err != nil {
+		t.Fatal("Error updating manifest. " + err.Error())
+	}
+	if _, err := client.WaitForManifestBuild(ctx, skillID); err != nil {
+		t.Fatal("Error waiting for manifest build. " + err.Error())
+	}
+
+	got, err := client.GetManifest(ctx, skillID, smapi.StageDevelopment)
+	if err != nil {
+		t.Fatal("Error getting manifest. " + err.Error())
+	}
+	if got.Manifest.PublishingInformation.Locales["en-US"].Name != "HelloWorld" {
+		t.Error("Expected manifest name to be HelloWorld but was", got.Manifest.PublishingInformation.Locales["en-US"].Name)
+	}
+}
+
+func TestSimulateUtterance(t *testing.T) {
+	fake, client := newFake()
+	defer fake.Close()
+	ctx := context.Background()
+	fake.SetInteractionModel(smapi.StageDevelopment, "en-US", newModel("hello world"))
+
+	sim, err := client.SimulateUtterance(ctx, skillID, smapi.StageDevelopment, "en-US", "ask hello world to say hello to Ada")
+	if err != nil {
+		t.Fatal("Error simulating utterance. " + err.Error())
+	}
+	invocations := sim.Result.SkillExecutionInfo.Invocations
+	if len(invocations) != 1 || invocations[0].InvocationRequest.Body.Request.Intent.Name != "GreetIntent" {
+		t.Fatal("Expected utterance to resolve to GreetIntent.")
+	}
+	if caption := sim.Result.AlexaExecutionInfo.AlexaResponses[0].Content.Caption; caption != "Hello ada" {
+		t.Error("Expected caption to be 'Hello ada' but was", caption)
+	}
+
+	sim, err = client.SimulateUtterance(ctx, skillID, smapi.StageDevelopment, "en-US", "open hello world")
+	if err != nil {
+		t.Fatal("Error simulating utterance. " + err.Error())
+	}
+	if sim.Result.AlexaExecutionInfo.AlexaResponses[0].Content.Caption != "Welcome" {
+		t.Error("Expected launch to respond with Welcome.")
+	}
+
+	_, err = client.SimulateUtterance(ctx, skillID, smapi.StageDevelopment, "en-US", "ask hello world to dance")
+	if !errors.Is(err, smapi.ErrSimulationFailed) {
+		t.Error("Expected ErrSimulationFailed for an unhandled intent but got", err)
+	}
+}
+
+func TestInvokeSkill(t *testing.T) {
+	fake, client := newFake()
+	defer fake.Close()
+
+	requestEnv := &alexa.RequestEnvelope{Version: "1.0", Session: &alexa.Session{}, Request: &alexa.Request{}, Context: &alexa.Context{}}
+	requestEnv.Session.Application.ApplicationID = skillID
+	requestEnv.Request.Type = "LaunchRequest"
+	requestEnv.Request.Timestamp = "2016-10-27T21:06:28Z"
+
+	// The fake skill validates timestamps, so the stale timestamp fails.
+	result, err := client.InvokeSkill(context.Background(), skillID, smapi.StageDevelopment, fake.Region(), requestEnv)
+	if !errors.Is(err, smapi.ErrSimulationFailed) || result.Result.Error == nil {
+		t.Error("Expected invocation with a stale timestamp to fail but got", err)
+	}
+
+	fake.Skill.IgnoreTimestamp = true
+	result, err = client.InvokeSkill(context.Background(), skillID, smapi.StageDevelopment, fake.Region(), requestEnv)
+	if err != nil {
+		t.Fatal("Error invoking skill. " + err.Error())
+	}
+	if result.Result.SkillExecutionInfo.InvocationResponse.Body.Response.OutputSpeech.Text != "Welcome" {
+		t.Error("Expected invocation to respond with Welcome.")
+	}
+}
+
+func TestUnauthorized(t *testing.T) {
+	fake, _ := newFake()
+	defer fake.Close()
+
+	client := &smapi.Client{Region: fake.Region(), Tokens: staticToken("Atza|wrong")}
+	_, err := client.GetManifest(context.Background(), skillID, smapi.StageDevelopment)
+	if !errors.Is(err, alexa.ErrUnauthorized) {
+		t.Error("Expected ErrUnauthorized but got", err)
+	}
+}
+
+type staticToken string
+
+func (s staticToken) Token(context.Context) (string, error) {
+	return string(s), nil
+}
@@ -0,0 +1,400 @@
+// Package smapitest provides an offline fake of the Skill Management API and
+// of Login with Amazon, so that code using the smapi package can be tested
+// without a network connection or credentials.
+package smapitest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	alexa "github.com/ericdaugherty/alexa-skills-kit-golang"
+	"github.com/ericdaugherty/alexa-skills-kit-golang/smapi"
+)
+
+// Token is the access token issued by the fake LWA endpoint.
+const Token = "Atza|smapitest"
+
+// Server is a fake Skill Management API for a single skill. Builds complete
+// after BuildPolls status checks, and fail if a development interaction model
+// has no invocation name. Simulations and invocations are answered by Skill,
+// if set. Simulated utterances are matched against the sample utterances of
+// the stored interaction model.
+type Server struct {
+	SkillID    string
+	Skill      *alexa.Alexa
+	BuildPolls int
+
+	server *httptest.Server
+
+	mu        sync.Mutex
+	manifests map[string]*alexa.SkillManifest
+	models    map[string]*alexa.InteractionModel
+	pending   map[string]int
+	sims      map[string]*smapi.Simulation
+	nextSimID int
+}
+
+// NewServer starts a fake for skillID. The caller must call Close when done.
+func NewServer(skillID string, skill *alexa.Alexa) *Server {
+	s := &Server{
+		SkillID:   skillID,
+		Skill:     skill,
+		manifests: make(map[string]*alexa.SkillManifest),
+		models:    make(map[string]*alexa.InteractionModel),
+		pending:   make(map[string]int),
+		sims:      make(map[string]*smapi.Simulation),
+	}
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// URL returns the base URL of the fake.
+func (s *Server) URL() string {
+	return s.server.URL
+}
+
+// Close shuts down the fake.
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// Region returns a region whose API and token endpoints are the fake.
+func (s *Server) Region() alexa.Region {
+	return alexa.Region{Name: "NA", APIEndpoint: s.server.URL, TokenEndpoint: s.server.URL + "/auth/o2/token"}
+}
+
+// Client returns a smapi.Client that calls the fake.
+func (s *Server) Client() *smapi.Client {
+	c := smapi.NewClient(s.Region(), "smapitest", "smapitest", "Atzr|smapitest")
+	c.PollInterval = time.Millisecond
+	return c
+}
+
+// SetManifest stores the manifest for stage.
+func (s *Server) SetManifest(stage string, manifest *alexa.SkillManifest) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.manifests[stage] = manifest
+}
+
+// Manifest returns the manifest stored for stage.
+func (s *Server) Manifest(stage string) *alexa.SkillManifest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.manifests[stage]
+}
+
+// SetInteractionModel stores the interaction model for stage and locale.
+func (s *Server) SetInteractionModel(stage, locale string, model *alexa.InteractionModel) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.models[stage+"/"+locale] = model
+}
+
+// InteractionModel returns the interaction model stored for stage and locale.
+func (s *Server) InteractionModel(stage, locale string) *alexa.InteractionModel {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.models[stage+"/"+locale]
+}
+
+var routes = []struct {
+	method  string
+	pattern *regexp.Regexp
+	handle  func(s *Server, w http.ResponseWriter, r *http.Request, m []string)
+}{
+	{http.MethodGet, regexp.MustCompile(`^/v1/skills/([^/]+)/stages/([^/]+)/manifest$`), (*Server).getManifest},
+	{http.MethodPut, regexp.MustCompile(`^/v1/skills/([^/]+)/stages/([^/]+)/manifest$`), (*Server).putManifest},
+	{http.MethodGet, regexp.MustCompile(`^/v1/skills/([^/]+)/stages/([^/]+)/interactionModel/locales/([^/]+)$`), (*Server).getModel},
+	{http.MethodPut, regexp.MustCompile(`^/v1/skills/([^/]+)/stages/([^/]+)/interactionModel/locales/([^/]+)$`), (*Server).putModel},
+	{http.MethodGet, regexp.MustCompile(`^/v1/skills/([^/]+)/status$`), (*Server).getStatus},
+	{http.MethodPost, regexp.MustCompile(`^/v2/skills/([^/]+)/stages/([^/]+)/simulations$`), (*Server).postSimulation},
+	{http.MethodGet, regexp.MustCompile(`^/v2/skills/([^/]+)/stages/([^/]+)/simulations/([^/]+)$`), (*Server).getSimulation},
+	{http.MethodPost, regexp.MustCompile(`^/v2/skills/([^/]+)/stages/([^/]+)/invocations$`), (*Server).postInvocation},
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/auth/o2/token" {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"access_token": Token, "expires_in": 3600, "token_type": "bearer"})
+		return
+	}
+	if r.Header.Get("Authorization") != "Bearer "+Token {
+		writeError(w, http.StatusUnauthorized, "No access token or invalid access token provided.")
+		return
+	}
+
+	for _, route := range routes {
+		m := route.pattern.FindStringSubmatch(r.URL.Path)
+		if m == nil {
+			continue
+		}
+		if m[1] != s.SkillID {
+			writeError(w, http.StatusNotFound, "The resource being requested is not found.")
+			return
+		}
+		if r.Method != route.method {
+			continue
+		}
+		s.mu.Lock()
+		route.handle(s, w, r, m)
+		s.mu.Unlock()
+		return
+	}
+	writeError(w, http.StatusNotFound, "The resource being requested is not found.")
+}
+
+func (s *Server) getManifest(w http.ResponseWriter, r *http.Request, m []string) {
+	manifest, ok := s.manifests[m[2]]
+	if !ok {
+		writeError(w, http.StatusNotFound, "The manifest was not found.")
+		return
+	}
+	writeJSON(w, http.StatusOK, manifest)
+}
+
+func (s *Server) putManifest(w http.ResponseWriter, r *http.Request, m []string) {
+	manifest := &alexa.SkillManifest{}
+	if err := json.NewDecoder(r.Body).Decode(manifest); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.manifests[m[2]] = manifest
+	s.pending["manifest"] = s.BuildPolls
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *Server) getModel(w http.ResponseWriter, r *http.Request, m []string) {
+	model, ok := s.models[m[2]+"/"+m[3]]
+	if !ok {
+		writeError(w, http.StatusNotFound, "The interaction model was not found.")
+		return
+	}
+	writeJSON(w, http.StatusOK, model)
+}
+
+func (s *Server) putModel(w http.ResponseWriter, r *http.Request, m []string) {
+	model := &alexa.InteractionModel{}
+	if err := json.NewDecoder(r.Body).Decode(model); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.models[m[2]+"/"+m[3]] = model
+	s.pending[m[3]] = s.BuildPolls
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *Server) getStatus(w http.ResponseWriter, r *http.Request, m []string) {
+	status := map[string]interface{}{}
+	models := map[string]interface{}{}
+	for resource, polls := range s.pending {
+		build := map[string]interface{}{"status": smapi.StatusSucceeded}
+		if polls > 0 {
+			build["status"] = smapi.StatusInProgress
+			s.pending[resource] = polls - 1
+		} else if resource != "manifest" {
+			if model := s.models[smapi.StageDevelopment+"/"+resource]; model != nil && model.InteractionModel.LanguageModel.InvocationName == "" {
+				build["status"] = smapi.StatusFailed
+				build["errors"] = []map[string]string{{"code": "INVALID_INTERACTION_MODEL", "message": "Invocation name must not be empty."}}
+			}
+		}
+		if resource == "manifest" {
+			status["manifest"] = map[string]interface{}{"lastUpdateRequest": build}
+		} else {
+			models[resource] = map[string]interface{}{"lastUpdateRequest": build}
+		}
+	}
+	if len(models) > 0 {
+		status["interactionModel"] = models
+	}
+	writeJSON(w, http.StatusOK, status)
+}
+
+func (s *Server) postSimulation(w http.ResponseWriter, r *http.Request, m []string) {
+	var body struct {
+		Input struct {
+			Content string `json:"content"`
+		} `json:"input"`
+		Device struct {
+			Locale string `json:"locale"`
+		} `json:"device"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.nextSimID++
+	sim := &smapi.Simulation{ID: strconv.Itoa(s.nextSimID), Status: smapi.StatusInProgress}
+	s.sims[sim.ID] = sim
+	writeJSON(w, http.StatusOK, sim)
+
+	// The simulation completes by the time it is next polled.
+	done := &smapi.Simulation{ID: sim.ID, Status: smapi.StatusSuccessful, Result: &smapi.SimulationResult{}}
+	s.sims[sim.ID] = done
+
+	model := s.models[m[2]+"/"+body.Device.Locale]
+	if model == nil {
+		done.Status = smapi.StatusFailed
+		done.Result.Error = &smapi.ExecutionError{Message: "No interaction model for locale " + body.Device.Locale}
+		return
+	}
+	inv, err := s.invoke(r.Context(), s.requestFor(model, body.Device.Locale, body.Input.Content))
+	if err != nil {
+		done.Status = smapi.StatusFailed
+		done.Result.Error = &smapi.ExecutionError{Message: err.Error()}
+		return
+	}
+	done.Result.SkillExecutionInfo.Invocations = []smapi.Invocation{*inv}
+	if speech := inv.InvocationResponse.Body.Response.OutputSpeech; speech != nil {
+		response := smapi.AlexaResponse{Type: "Speech"}
+		response.Content.Caption = speech.Text
+		if speech.Type == "SSML" {
+			response.Content.Caption = strings.TrimSpace(tagPattern.ReplaceAllString(speech.SSML, ""))
+		}
+		done.Result.AlexaExecutionInfo.AlexaResponses = append(done.Result.AlexaExecutionInfo.AlexaResponses, response)
+	}
+}
+
+func (s *Server) getSimulation(w http.ResponseWriter, r *http.Request, m []string) {
+	sim, ok := s.sims[m[3]]
+	if !ok {
+		writeError(w, http.StatusNotFound, "The simulation was not found.")
+		return
+	}
+	writeJSON(w, http.StatusOK, sim)
+}
+
+func (s *Server) postInvocation(w http.ResponseWriter, r *http.Request, m []string) {
+	var body struct {
+		SkillRequest struct {
+			Body *alexa.RequestEnvelope `json:"body"`
+		} `json:"skillRequest"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.SkillRequest.Body == nil {
+		writeError(w, http.StatusBadRequest, "The skill request body is missing or invalid.")
+		return
+	}
+
+	result := &smapi.InvocationResult{Result: &smapi.InvocationOutput{}}
+	inv, err := s.invoke(r.Context(), body.SkillRequest.Body)
+	if err != nil {
+		result.Status = smapi.StatusFailed
+		result.Result.Error = &smapi.ExecutionError{Message: err.Error()}
+	} else {
+		result.Status = smapi.StatusSuccessful
+		result.Result.SkillExecutionInfo = *inv
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) invoke(ctx context.Context, requestEnv *alexa.RequestEnvelope) (*smapi.Invocation, error) {
+	inv := &smapi.Invocation{}
+	inv.InvocationRequest.Endpoint = "smapitest"
+	inv.InvocationRequest.Body = requestEnv
+	if s.Skill == nil {
+		return nil, errSkillNotSet
+	}
+	responseEnv, err := s.Skill.ProcessRequest(ctx, requestEnv)
+	if err != nil {
+		return nil, err
+	}
+	inv.InvocationResponse.Body = responseEnv
+	return inv, nil
+}
+
+// requestFor builds the request Alexa would send for utterance, resolving it
+// against the sample utterances of model.
+func (s *Server) requestFor(model *alexa.InteractionModel, locale, utterance string) *alexa.RequestEnvelope {
+	requestEnv := &alexa.RequestEnvelope{Version: "1.0", Session: &alexa.Session{}, Request: &alexa.Request{}, Context: &alexa.Context{}}
+	requestEnv.Session.New = true
+	requestEnv.Session.SessionID = "amzn1.echo-api.session.smapitest"
+	requestEnv.Session.Application.ApplicationID = s.SkillID
+	requestEnv.Session.User.UserID = "amzn1.ask.account.smapitest"
+	requestEnv.Context.System.Application.ApplicationID = s.SkillID
+	requestEnv.Context.System.User.UserID = "amzn1.ask.account.smapitest"
+	requestEnv.Context.System.Device.DeviceID = "amzn1.ask.device.smapitest"
+	requestEnv.Request.RequestID = "amzn1.echo-api.request.smapitest." + strconv.Itoa(s.nextSimID)
+	requestEnv.Request.Locale = locale
+	requestEnv.Request.Timestamp = time.Now().UTC().Format(time.RFC3339)
+
+	lm := model.InteractionModel.LanguageModel
+	u := normalize(utterance)
+	invocation := normalize(lm.InvocationName)
+	for _, prefix := range []string{"open ", "launch ", "start "} {
+		if u == prefix+invocation {
+			requestEnv.Request.Type = "LaunchRequest"
+			return requestEnv
+		}
+	}
+	for _, prefix := range []string{"ask " + invocation + " to ", "ask " + invocation + " ", "tell " + invocation + " to "} {
+		u = strings.TrimPrefix(u, prefix)
+	}
+
+	requestEnv.Request.Type = "IntentRequest"
+	for _, intent := range lm.Intents {
+		for _, sample := range intent.Samples {
+			if slots, ok := matchSample(normalize(sample), u); ok {
+				requestEnv.Request.Intent.Name = intent.Name
+				requestEnv.Request.Intent.ConfirmationStatus = "NONE"
+				requestEnv.Request.Intent.Slots = make(map[string]alexa.IntentSlot)
+				for _, slot := range intent.Slots {
+					requestEnv.Request.Intent.Slots[slot.Name] = alexa.IntentSlot{Name: slot.Name, ConfirmationStatus: "NONE", Value: slots[slot.Name]}
+				}
+				return requestEnv
+			}
+		}
+	}
+	requestEnv.Request.Intent.Name = "AMAZON.FallbackIntent"
+	return requestEnv
+}
+
+var slotPattern = regexp.MustCompile(`\{([^}]+)\}`)
+var tagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// matchSample matches utterance against a sample utterance, returning the
+// values of the slots in the sample.
+func matchSample(sample, utterance string) (map[string]string, bool) {
+	names := []string{}
+	pattern := "^"
+	last := 0
+	for _, loc := range slotPattern.FindAllStringSubmatchIndex(sample, -1) {
+		pattern += regexp.QuoteMeta(sample[last:loc[0]]) + "(.+?)"
+		names = append(names, sample[loc[2]:loc[3]])
+		last = loc[1]
+	}
+	pattern += regexp.QuoteMeta(sample[last:]) + "$"
+
+	m := regexp.MustCompile(pattern).FindStringSubmatch(utterance)
+	if m == nil {
+		return nil, false
+	}
+	slots := make(map[string]string)
+	for i, name := range names {
+		slots[name] = m[i+1]
+	}
+	return slots, true
+}
+
+func normalize(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
+
+var errSkillNotSet = errors.New("smapitest: no skill is set on the fake server")
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"message": message})
+}
@@ -0,0 +1,169 @@
+package alexa
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrUnknownTenant reports that no Tenant has the application ID of a request.
+var ErrUnknownTenant = errors.New("no tenant for request Application ID")
+
+type tenantKey struct{}
+
+// Tenant configures one brand of a white-label skill. Each brand is published
+// as its own skill, with its own application ID, sharing one codebase.
+//
+// The speech of every response is spoken in the Voice of the tenant, and
+// standard cards without an image get its CardImage. The methods of Tenant
+// localize text and map product IDs. They are safe to call on nil, and then
+// use the values passed to them, so handlers can use them whether or not
+// Alexa has Tenants.
+type Tenant struct {
+	Name          string
+	ApplicationID string
+	// Voice is the Amazon Polly voice of the speech. Empty for the Alexa
+	// voice.
+	Voice string
+	// CardImage is used for the image of standard cards.
+	CardImage Image
+	// ProductIDs maps the reference names of in-skill products to the
+	// product IDs of the tenant.
+	ProductIDs map[string]string
+	// Strings maps a locale, such as en-US, or a language, such as en, to the
+	// text for each key.
+	Strings map[string]map[string]string
+}
+
+// TenantFor returns the Tenant with applicationID, or nil.
+func (alexa *Alexa) TenantFor(applicationID string) *Tenant {
+	for _, t := range alexa.Tenants {
+		if t.ApplicationID == applicationID {
+			return t
+		}
+	}
+	return nil
+}
+
+// WithTenant returns a copy of ctx carrying tenant.
+func WithTenant(ctx context.Context, tenant *Tenant) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// TenantFromContext returns the Tenant of the request, or nil if Alexa has no
+// Tenants.
+func TenantFromContext(ctx context.Context) *Tenant {
+	t, _ := ctx.Value(tenantKey{}).(*Tenant)
+	return t
+}
+
+// Tenant returns the Tenant of the request, or nil if Alexa has no Tenants.
+func (in *HandlerInput) Tenant() *Tenant {
+	return TenantFromContext(in.Ctx)
+}
+
+// resolveTenant adds the Tenant of requestEnv to ctx. When the application ID
+// is not verified, requests from unknown applications use the first Tenant.
+func (alexa *Alexa) resolveTenant(ctx context.Context, requestEnv *RequestEnvelope) (context.Context, error) {
+	if len(alexa.Tenants) == 0 {
+		return ctx, nil
+	}
+	t := alexa.TenantFor(requestApplicationID(requestEnv))
+	if t == nil {
+		if !alexa.IgnoreApplicationID {
+			return ctx, ErrUnknownTenant
+		}
+		t = alexa.Tenants[0]
+	}
+	return WithTenant(ctx, t), nil
+}
+
+// requestApplicationID returns the application ID of the session, or of the
+// context for requests outside a session, such as AudioPlayer events.
+func requestApplicationID(requestEnv *RequestEnvelope) string {
+	if requestEnv.Session != nil && requestEnv.Session.Application.ApplicationID != "" {
+		return requestEnv.Session.Application.ApplicationID
+	}
+	if requestEnv.Context != nil {
+		return requestEnv.Context.System.Application.ApplicationID
+	}
+	return ""
+}
+
+// applyTenant speaks the response in the Voice of the tenant in ctx, and sets
+// its CardImage on a standard card without an image.
+func applyTenant(ctx context.Context, response *Response) {
+	t := TenantFromContext(ctx)
+	if t == nil {
+		return
+	}
+	if t.Voice != "" {
+		t.voice(response.OutputSpeech)
+		if response.Reprompt != nil {
+			t.voice(response.Reprompt.OutputSpeech)
+		}
+	}
+	if c := response.Card; c != nil && c.Type == "Standard" && (c.Image == nil || *c.Image == Image{}) && t.CardImage != (Image{}) {
+		image := t.CardImage
+		c.Image = &image
+	}
+}
+
+// voice converts speech to SSML in the Voice of the tenant, unless it already
+// sets a voice.
+func (t *Tenant) voice(speech *OutputSpeech) {
+	switch {
+	case speech == nil:
+	case speech.Type == "PlainText":
+		speech.Type = "SSML"
+		speech.SSML = t.SSML(ssmlEscaper.Replace(speech.Text))
+		speech.Text = ""
+	case speech.Type == "SSML" && !strings.Contains(speech.SSML, "<voice"):
+		speech.SSML = t.SSML(speech.SSML)
+	}
+}
+
+var ssmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// Text returns the text for key in locale, formatted with args. The locale is
+// tried first, then its language. If neither has key, key is used as the
+// text.
+func (t *Tenant) Text(locale, key string, args ...interface{}) string {
+	text := key
+	if t != nil {
+		if s, ok := t.Strings[locale][key]; ok {
+			text = s
+		} else if s, ok := t.Strings[strings.SplitN(locale, "-", 2)[0]][key]; ok {
+			text = s
+		}
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(text, args...)
+	}
+	return text
+}
+
+// SSML returns ssml, with or without its speak element, as a speak element
+// spoken in the Voice of the tenant. Responses are spoken in the voice without
+// it; use it for SSML sent elsewhere, such as in APL documents.
+func (t *Tenant) SSML(ssml string) string {
+	ssml = strings.TrimSpace(ssml)
+	ssml = strings.TrimPrefix(ssml, "<speak>")
+	ssml = strings.TrimSuffix(ssml, "</speak>")
+	if t != nil && t.Voice != "" {
+		ssml = `<voice name="` + t.Voice + `">` + ssml + "</voice>"
+	}
+	return "<speak>" + ssml + "</speak>"
+}
+
+// ProductID returns the product ID of the tenant for the in-skill product
+// referenceName. Without a tenant, or a mapping, referenceName is returned.
+func (t *Tenant) ProductID(referenceName string) string {
+	if t != nil {
+		if id, ok := t.ProductIDs[referenceName]; ok {
+			return id
+		}
+	}
+	return referenceName
+}
@@ -0,0 +1,88 @@
+package alexa
+
+import (
+	"context"
+	"testing"
+)
+
+type tenantHandler struct {
+	emptyRequestHandler
+}
+
+func (h *tenantHandler) OnIntent(ctx context.Context, request *Request, session *Session, aContext *Context, response *Response) error {
+	t := TenantFromContext(ctx)
+	response.SetOutputText(t.Text(request.Locale, "welcome", t.Name))
+	response.SetStandardCard("Offer", t.ProductID("premium"), "", "")
+	return nil
+}
+
+func getTenants() []*Tenant {
+	return []*Tenant{
+		{
+			Name:          "Acme",
+			ApplicationID: "amzn1.ask.skill.acme",
+			Voice:         "Joanna",
+			CardImage:     Image{SmallImageURL: "https://acme.example/s.png", LargeImageURL: "https://acme.example/l.png"},
+			ProductIDs:    map[string]string{"premium": "amzn1.adg.product.acme"},
+			Strings:       map[string]map[string]string{"en": {"welcome": "Welcome to %s"}},
+		},
+		{
+			Name:          "Globex",
+			ApplicationID: "amzn1.ask.skill.globex",
+			Strings:       map[string]map[string]string{"en-US": {"welcome": "Hi from %s"}},
+		},
+	}
+}
+
+func TestTenantResolvedFromApplicationID(t *testing.T) {
+	alexa := &Alexa{RequestHandler: &tenantHandler{}, Tenants: getTenants()}
+
+	request := createRecipeRequest()
+	request.Session.Application.ApplicationID = "amzn1.ask.skill.acme"
+	response, err := alexa.ProcessRequest(context.Background(), request)
+	if err != nil {
+		t.Fatal("Error processing request. " + err.Error())
+	}
+	if response.Response.OutputSpeech.SSML != `<speak><voice name="Joanna">Welcome to Acme</voice></speak>` {
+		t.Error("Expected tenant speech but was", response.Response.OutputSpeech.SSML)
+	}
+	if response.Response.Card.Text != "amzn1.adg.product.acme" || response.Response.Card.Image.LargeImageURL != "https://acme.example/l.png" {
+		t.Error("Expected tenant card but was", response.Response.Card)
+	}
+
+	request = createRecipeRequest()
+	request.Session.Application.ApplicationID = "amzn1.ask.skill.globex"
+	response, _ = alexa.ProcessRequest(context.Background(), request)
+	if response.Response.OutputSpeech.Text != "Hi from Globex" || response.Response.Card.Image != nil && response.Response.Card.Image.LargeImageURL != "" {
+		t.Error("Expected tenant speech in the Alexa voice and no card image but was", response.Response.OutputSpeech, response.Response.Card.Image)
+	}
+	if response.Response.Card.Text != "premium" {
+		t.Error("Expected unmapped product to use the reference name but was", response.Response.Card.Text)
+	}
+
+	// Requests outside a session carry the application ID in the context.
+	request = createRecipeRequest()
+	request.Session = nil
+	request.Context = &Context{}
+	request.Context.System.Application.ApplicationID = "amzn1.ask.skill.acme"
+	response, err = alexa.ProcessRequest(context.Background(), request)
+	if err != nil || response.Response.Card.Text != "amzn1.adg.product.acme" {
+		t.Error("Expected the tenant of the context but got", response, err)
+	}
+
+	request = createRecipeRequest()
+	request.Session.Application.ApplicationID = "amzn1.ask.skill.other"
+	if _, err := alexa.ProcessRequest(context.Background(), request); err != ErrUnknownTenant {
+		t.Error("Expected ErrUnknownTenant but got", err)
+	}
+}
+
+func TestNilTenant(t *testing.T) {
+	var tenant *Tenant
+	if tenant.Text("en-US", "Hello %s", "there") != "Hello there" {
+		t.Error("Expected nil tenant to use the key as text.")
+	}
+	if tenant.SSML("<speak>Hi</speak>") != "<speak>Hi</speak>" {
+		t.Error("Expected nil tenant to keep the default voice.")
+	}
+}
@@ -0,0 +1,234 @@
+// Package verification asks Alexa to verify the recognized speaker with the
+// AMAZON.VerifyPerson task, such as by their voice PIN, before account-level
+// actions.
+//
+// Gate requires verification before the sensitive intents of a skill. A
+// speaker is verified for the rest of the session, as long as Alexa still
+// recognizes the same person.
+package verification
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	alexa "github.com/ericdaugherty/alexa-skills-kit-golang"
+)
+
+// URI is the connection URI of the AMAZON.VerifyPerson task.
+const URI = "connection://AMAZON.VerifyPerson/2"
+
+const taskName = "AMAZON.VerifyPerson"
+
+// DefaultLevel is the authentication confidence level of a voice PIN.
+const DefaultLevel = 400
+
+// Session attributes used by Gate.
+const (
+	// VerifiedAttribute holds the person ID of the verified speaker.
+	VerifiedAttribute = "verifiedPersonId"
+	// PendingAttribute holds the intent waiting for verification.
+	PendingAttribute = "verificationPending"
+)
+
+// ErrNoResult reports that a request is not the result of a VerifyPerson
+// task started with this package.
+var ErrNoResult = errors.New("request is not a VerifyPerson result")
+
+// Policy is a custom authentication policy, such as VOICE_PIN.
+type Policy struct {
+	PolicyName string `json:"policyName"`
+}
+
+// ConfidenceLevel is the level of confidence in the identity of the speaker.
+type ConfidenceLevel struct {
+	Level        int     `json:"level"`
+	CustomPolicy *Policy `json:"customPolicy,omitempty"`
+}
+
+// Input is the input of the VerifyPerson task.
+type Input struct {
+	RequestedAuthenticationConfidenceLevel ConfidenceLevel `json:"requestedAuthenticationConfidenceLevel"`
+}
+
+// Start adds a directive to r starting the VerifyPerson task at level. The
+// result has token.
+func Start(r *alexa.Response, level ConfidenceLevel, token string) {
+	r.AddConnectionsStartConnection(URI, Input{level}, taskName+"|"+token)
+}
+
+// Result is the result of a VerifyPerson task.
+type Result struct {
+	// Token is the token the task was started with.
+	Token string
+	// Status is the status code of the task, such as 200.
+	Status  string
+	Message string
+	// Level is the confidence level reached.
+	Level ConfidenceLevel
+}
+
+// Verified reports whether the task completed with at least level.
+func (r *Result) Verified(level int) bool {
+	return r.Status == "200" && r.Level.Level >= level
+}
+
+// ResultFromRequest returns the Result of a VerifyPerson task started by
+// Start, or ErrNoResult if request is not one.
+func ResultFromRequest(request *alexa.Request) (*Result, error) {
+	cause := request.Cause
+	if cause == nil {
+		return nil, ErrNoResult
+	}
+	task, token, ok := strings.Cut(cause.Token, "|")
+	if !ok || task != taskName {
+		return nil, ErrNoResult
+	}
+
+	r := &Result{Token: token, Status: cause.Status.Code, Message: cause.Status.Message}
+	if len(cause.Result) > 0 && string(cause.Result) != "null" {
+		var result struct {
+			AuthenticationConfidenceLevel ConfidenceLevel `json:"authenticationConfidenceLevel"`
+		}
+		if err := json.Unmarshal(cause.Result, &result); err != nil {
+			return nil, err
+		}
+		r.Level = result.AuthenticationConfidenceLevel
+	}
+	return r, nil
+}
+
+// PersonID returns the ID of the speaker Alexa recognized, or "".
+func PersonID(aContext *alexa.Context) string {
+	if aContext == nil || aContext.System.Person == nil {
+		return ""
+	}
+	return aContext.System.Person.PersonID
+}
+
+// IsVerified reports whether the recognized speaker was verified in this
+// session.
+func IsVerified(session *alexa.Session, aContext *alexa.Context) bool {
+	id := PersonID(aContext)
+	return id != "" && session.Attributes.String[VerifiedAttribute] == id
+}
+
+// Prompts are the speech of a Gate.
+type Prompts struct {
+	NotRecognized string
+	NotVerified   string
+}
+
+// DefaultPrompts are used for the Prompts left empty.
+var DefaultPrompts = Prompts{
+	NotRecognized: "I don't recognize your voice. To do that, set up a voice profile in the Alexa app.",
+	NotVerified:   "Sorry, I couldn't verify your identity.",
+}
+
+// Gate is an alexa.RequestHandler that verifies the recognized speaker before
+// passing the sensitive intents to Handler. Once verified, the intent that
+// started verification is passed on. Other SessionResumedRequests are passed
+// to Handler, if it is an alexa.SessionResumedHandler.
+type Gate struct {
+	Handler alexa.RequestHandler
+	// Intents are the names of the sensitive intents.
+	Intents map[string]bool
+	// Level to verify at. Default is DefaultLevel.
+	Level   ConfidenceLevel
+	Prompts Prompts
+}
+
+// NewGate creates a Gate passing requests to handler, with the named intents
+// as sensitive.
+func NewGate(handler alexa.RequestHandler, intents ...string) *Gate {
+	g := &Gate{Handler: handler, Intents: make(map[string]bool), Level: ConfidenceLevel{Level: DefaultLevel}}
+	for _, i := range intents {
+		g.Intents[i] = true
+	}
+	return g
+}
+
+// OnSessionStarted calls Handler.
+func (g *Gate) OnSessionStarted(ctx context.Context, request *alexa.Request, session *alexa.Session, aContext *alexa.Context, response *alexa.Response) error {
+	return g.Handler.OnSessionStarted(ctx, request, session, aContext, response)
+}
+
+// OnLaunch calls Handler.
+func (g *Gate) OnLaunch(ctx context.Context, request *alexa.Request, session *alexa.Session, aContext *alexa.Context, response *alexa.Response) error {
+	return g.Handler.OnLaunch(ctx, request, session, aContext, response)
+}
+
+// OnIntent starts verification before sensitive intents of a speaker not
+// verified, and passes other intents to Handler.
+func (g *Gate) OnIntent(ctx context.Context, request *alexa.Request, session *alexa.Session, aContext *alexa.Context, response *alexa.Response) error {
+	if !g.Intents[request.Intent.Name] || IsVerified(session, aContext) {
+		return g.Handler.OnIntent(ctx, request, session, aContext, response)
+	}
+	if PersonID(aContext) == "" {
+		response.SetOutputText(g.prompts().NotRecognized)
+		return nil
+	}
+	session.Attributes.String[PendingAttribute] = request.Intent
+	Start(response, g.level(), request.Intent.Name)
+	return nil
+}
+
+// OnSessionResumed passes the pending intent to Handler once the speaker is
+// verified.
+func (g *Gate) OnSessionResumed(ctx context.Context, request *alexa.Request, session *alexa.Session, aContext *alexa.Context, response *alexa.Response) error {
+	result, err := ResultFromRequest(request)
+	if err == ErrNoResult {
+		if h, ok := g.Handler.(alexa.SessionResumedHandler); ok {
+			return h.OnSessionResumed(ctx, request, session, aContext, response)
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var pending alexa.Intent
+	ok, err := alexa.DecodeAttribute(session.Attributes.String, PendingAttribute, &pending)
+	if err != nil {
+		return err
+	}
+	delete(session.Attributes.String, PendingAttribute)
+	id := PersonID(aContext)
+	if !result.Verified(g.level().Level) || id == "" {
+		response.SetOutputText(g.prompts().NotVerified)
+		return nil
+	}
+	session.Attributes.String[VerifiedAttribute] = id
+	if !ok {
+		return nil
+	}
+	resumed := *request
+	resumed.Type = "IntentRequest"
+	resumed.Intent = pending
+	return g.Handler.OnIntent(ctx, &resumed, session, aContext, response)
+}
+
+// OnSessionEnded calls Handler.
+func (g *Gate) OnSessionEnded(ctx context.Context, request *alexa.Request, session *alexa.Session, aContext *alexa.Context, response *alexa.Response) error {
+	return g.Handler.OnSessionEnded(ctx, request, session, aContext, response)
+}
+
+func (g *Gate) level() ConfidenceLevel {
+	if g.Level.Level == 0 {
+		return ConfidenceLevel{Level: DefaultLevel, CustomPolicy: g.Level.CustomPolicy}
+	}
+	return g.Level
+}
+
+// prompts returns Prompts with DefaultPrompts for the empty ones.
+func (g *Gate) prompts() Prompts {
+	p := g.Prompts
+	if p.NotRecognized == "" {
+		p.NotRecognized = DefaultPrompts.NotRecognized
+	}
+	if p.NotVerified == "" {
+		p.NotVerified = DefaultPrompts.NotVerified
+	}
+	return p
+}
@@ -0,0 +1,101 @@
+package verification
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	alexa "github.com/ericdaugherty/alexa-skills-kit-golang"
+)
+
+type accountHandler struct {
+	closed int
+}
+
+func (h *accountHandler) OnSessionStarted(context.Context, *alexa.Request, *alexa.Session, *alexa.Context, *alexa.Response) error {
+	return nil
+}
+
+func (h *accountHandler) OnLaunch(context.Context, *alexa.Request, *alexa.Session, *alexa.Context, *alexa.Response) error {
+	return nil
+}
+
+func (h *accountHandler) OnIntent(ctx context.Context, request *alexa.Request, session *alexa.Session, aContext *alexa.Context, response *alexa.Response) error {
+	h.closed++
+	response.SetOutputText("Your account is closed.")
+	return nil
+}
+
+func (h *accountHandler) OnSessionEnded(context.Context, *alexa.Request, *alexa.Session, *alexa.Context, *alexa.Response) error {
+	return nil
+}
+
+func newRequest(requestType, personID string, attributes map[string]interface{}) *alexa.RequestEnvelope {
+	requestEnv := &alexa.RequestEnvelope{
+		Session: &alexa.Session{New: attributes == nil},
+		Request: &alexa.Request{Type: requestType, Intent: alexa.Intent{Name: "CloseAccountIntent"}},
+		Context: &alexa.Context{},
+	}
+	requestEnv.Session.Attributes.String = attributes
+	if personID != "" {
+		requestEnv.Context.System.Person = &alexa.Person{PersonID: personID}
+	}
+	return requestEnv
+}
+
+func resume(t *testing.T, responseEnv *alexa.ResponseEnvelope, personID string, level int) *alexa.RequestEnvelope {
+	var d alexa.ConnectionsDirective
+	if len(responseEnv.Response.Directives) == 1 {
+		d = responseEnv.Response.Directives[0].(alexa.ConnectionsDirective)
+	}
+	if d.URI != URI {
+		t.Fatal("Expected a VerifyPerson task but directives were", responseEnv.Response.Directives)
+	}
+
+	b, _ := json.Marshal(responseEnv.SessionAttributes)
+	attributes := make(map[string]interface{})
+	json.Unmarshal(b, &attributes)
+	requestEnv := newRequest("SessionResumedRequest", personID, attributes)
+	requestEnv.Request.Intent = alexa.Intent{}
+	requestEnv.Request.Cause = &alexa.Cause{Type: "ConnectionCompleted", Token: d.Token}
+	requestEnv.Request.Cause.Status.Code = "200"
+	requestEnv.Request.Cause.Result = json.RawMessage(`{"authenticationConfidenceLevel":{"level":` + strconv.Itoa(level) + `}}`)
+	return requestEnv
+}
+
+func TestGate(t *testing.T) {
+	h := &accountHandler{}
+	a := &alexa.Alexa{RequestHandler: NewGate(h, "CloseAccountIntent"), IgnoreApplicationID: true, IgnoreTimestamp: true}
+	ctx := context.Background()
+
+	responseEnv, _ := a.ProcessRequest(ctx, newRequest("IntentRequest", "", nil))
+	if responseEnv.Response.OutputSpeech.Text != DefaultPrompts.NotRecognized || h.closed != 0 {
+		t.Error("Expected an unrecognized speaker to be refused but was", responseEnv.Response.OutputSpeech.Text)
+	}
+
+	responseEnv, _ = a.ProcessRequest(ctx, newRequest("IntentRequest", "amzn1.ask.person.1", nil))
+	if h.closed != 0 {
+		t.Error("Expected the intent to wait for verification")
+	}
+	responseEnv, err := a.ProcessRequest(ctx, resume(t, responseEnv, "amzn1.ask.person.1", 400))
+	if err != nil {
+		t.Fatal("Error processing SessionResumedRequest. " + err.Error())
+	}
+	if responseEnv.Response.OutputSpeech.Text != "Your account is closed." || h.closed != 1 {
+		t.Error("Expected the pending intent to run once verified but speech was", responseEnv.Response.OutputSpeech)
+	}
+	if responseEnv.SessionAttributes[VerifiedAttribute] != "amzn1.ask.person.1" {
+		t.Error("Expected the speaker to be verified for the session but attributes were", responseEnv.SessionAttributes)
+	}
+
+	requestEnv := newRequest("IntentRequest", "amzn1.ask.person.2", responseEnv.SessionAttributes)
+	responseEnv, _ = a.ProcessRequest(ctx, requestEnv)
+	if h.closed != 1 || len(responseEnv.Response.Directives) != 1 {
+		t.Error("Expected another speaker to need verification")
+	}
+	responseEnv, _ = a.ProcessRequest(ctx, resume(t, responseEnv, "amzn1.ask.person.2", 100))
+	if responseEnv.Response.OutputSpeech.Text != DefaultPrompts.NotVerified || h.closed != 1 {
+		t.Error("Expected a low confidence level to be refused but speech was", responseEnv.Response.OutputSpeech)
+	}
+}
@@ -0,0 +1,163 @@
+package video
+
+import "encoding/json"
+
+// Entity types found in search and play requests.
+const (
+	EntityVideo      = "Video"
+	EntityActor      = "Actor"
+	EntityCharacter  = "Character"
+	EntityDirector   = "Director"
+	EntityFranchise  = "Franchise"
+	EntityGenre      = "Genre"
+	EntityMediaType  = "MediaType"
+	EntityChannel    = "Channel"
+	EntitySeason     = "Season"
+	EntityEpisode    = "Episode"
+	EntitySport      = "Sport"
+	EntitySportsTeam = "SportsTeam"
+	EntityApp        = "App"
+	EntityEvent      = "Event"
+)
+
+// Content types of playable items.
+const (
+	ContentOnDemand  = "ON_DEMAND"
+	ContentLive      = "LIVE"
+	ContentRecording = "RECORDING"
+)
+
+// Entity is something the user asked for, resolved against the catalog.
+type Entity struct {
+	Type           string            `json:"type"`
+	Value          string            `json:"value"`
+	ExternalIDs    map[string]string `json:"externalIds,omitempty"`
+	URI            string            `json:"uri,omitempty"`
+	Number         string            `json:"number,omitempty"`
+	EntityMetadata json.RawMessage   `json:"entityMetadata,omitempty"`
+}
+
+// EntitiesOfType returns the entities with type t.
+func EntitiesOfType(entities []Entity, t string) []Entity {
+	var matched []Entity
+	for _, e := range entities {
+		if e.Type == t {
+			matched = append(matched, e)
+		}
+	}
+	return matched
+}
+
+// TimeWindow limits a search to content airing between Start and End, in
+// ISO 8601 format.
+type TimeWindow struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// MediaIdentifier identifies an item of the catalog.
+type MediaIdentifier struct {
+	ID string `json:"id"`
+}
+
+// MediaItem is a search result.
+type MediaItem struct {
+	MediaIdentifier MediaIdentifier `json:"mediaIdentifier"`
+}
+
+// ItemsRequest is the payload of GetPlayableItems and GetDisplayableItems.
+type ItemsRequest struct {
+	Entities       []Entity    `json:"entities"`
+	ContentType    string      `json:"contentType,omitempty"`
+	Locale         string      `json:"locale"`
+	MinResultLimit int         `json:"minResultLimit"`
+	MaxResultLimit int         `json:"maxResultLimit"`
+	TimeWindow     *TimeWindow `json:"timeWindow,omitempty"`
+	NextToken      string      `json:"nextToken,omitempty"`
+}
+
+// ItemsResponse is the payload of GetPlayableItemsResponse and
+// GetDisplayableItemsResponse.
+type ItemsResponse struct {
+	NextToken  string      `json:"nextToken,omitempty"`
+	MediaItems []MediaItem `json:"mediaItems"`
+}
+
+// GetPlayableItemsMetadataRequest is the payload of GetPlayableItemsMetadata.
+type GetPlayableItemsMetadataRequest struct {
+	Locale          string          `json:"locale"`
+	MediaIdentifier MediaIdentifier `json:"mediaIdentifier"`
+}
+
+// GetPlayableItemsMetadataResponse is the payload of
+// GetPlayableItemsMetadataResponse.
+type GetPlayableItemsMetadataResponse struct {
+	SearchResults []PlayableItemMetadata `json:"searchResults"`
+}
+
+// PlayableItemMetadata describes how to play an item.
+type PlayableItemMetadata struct {
+	Name                                string           `json:"name"`
+	ContentType                         string           `json:"contentType"`
+	Series                              *Series          `json:"series,omitempty"`
+	PlaybackContextToken                string           `json:"playbackContextToken"`
+	ParentalControl                     *ParentalControl `json:"parentalControl,omitempty"`
+	AbsoluteViewingPositionMilliseconds int64            `json:"absoluteViewingPositionMilliseconds,omitempty"`
+}
+
+// Series places an episode in its series.
+type Series struct {
+	SeasonNumber  string `json:"seasonNumber,omitempty"`
+	EpisodeNumber string `json:"episodeNumber,omitempty"`
+	SeriesName    string `json:"seriesName,omitempty"`
+	EpisodeName   string `json:"episodeName,omitempty"`
+}
+
+// ParentalControl sets whether a PIN is REQUIRED or OPTIONAL to play an item.
+type ParentalControl struct {
+	PinControl string `json:"pinControl"`
+}
+
+// SearchText is the text of the user's request.
+type SearchText struct {
+	Transcribed string `json:"transcribed"`
+}
+
+// SearchRequest is the payload of SearchAndPlay and SearchAndDisplayResults.
+type SearchRequest struct {
+	Entities   []Entity     `json:"entities"`
+	SearchText []SearchText `json:"searchText,omitempty"`
+	TimeWindow *TimeWindow  `json:"timeWindow,omitempty"`
+}
+
+// Channel identifies a channel by any of its fields.
+type Channel struct {
+	Number            string `json:"number,omitempty"`
+	CallSign          string `json:"callSign,omitempty"`
+	AffiliateCallSign string `json:"affiliateCallSign,omitempty"`
+	URI               string `json:"uri,omitempty"`
+}
+
+// ChannelMetadata is the spoken name of a channel.
+type ChannelMetadata struct {
+	Name  string `json:"name,omitempty"`
+	Image string `json:"image,omitempty"`
+}
+
+// ChangeChannelRequest is the payload of ChangeChannel.
+type ChangeChannelRequest struct {
+	Channel         Channel          `json:"channel"`
+	ChannelMetadata *ChannelMetadata `json:"channelMetadata,omitempty"`
+}
+
+// SkipChannelsRequest is the payload of SkipChannels. ChannelCount is
+// negative to go down.
+type SkipChannelsRequest struct {
+	ChannelCount int `json:"channelCount"`
+}
+
+// ChannelResponse reports the channel tuned to by ChangeChannel or
+// SkipChannels.
+type ChannelResponse struct {
+	Channel Channel
+}
@@ -0,0 +1,341 @@
+// Package video implements the Video Skill API. Its directives use a different
+// envelope from custom skills, so Skill takes the place of alexa.Alexa and
+// Handler the place of alexa.RequestHandler.
+//
+// A custom skill and a video skill can share one Lambda function by setting
+// Skill.Custom and passing Skill.Invoke to lambda.StartHandler.
+package video
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	alexa "github.com/ericdaugherty/alexa-skills-kit-golang"
+	"github.com/ericdaugherty/alexa-skills-kit-golang/internal/uuid"
+)
+
+const payloadVersion = "3"
+
+// Namespaces of the directives handled by Skill.
+const (
+	NamespaceVideoContentProvider = "Alexa.VideoContentProvider"
+	NamespaceRemoteVideoPlayer    = "Alexa.RemoteVideoPlayer"
+	NamespaceChannelController    = "Alexa.ChannelController"
+)
+
+// Names of the directives handled by Skill.
+const (
+	GetPlayableItems         = "GetPlayableItems"
+	GetPlayableItemsMetadata = "GetPlayableItemsMetadata"
+	GetDisplayableItems      = "GetDisplayableItems"
+	SearchAndPlay            = "SearchAndPlay"
+	SearchAndDisplayResults  = "SearchAndDisplayResults"
+	ChangeChannel            = "ChangeChannel"
+	SkipChannels             = "SkipChannels"
+)
+
+// Error types returned to Alexa in an ErrorResponse.
+const (
+	ErrorInvalidValue                   = "INVALID_VALUE"
+	ErrorInvalidDirective               = "INVALID_DIRECTIVE"
+	ErrorInternal                       = "INTERNAL_ERROR"
+	ErrorEndpointUnreachable            = "ENDPOINT_UNREACHABLE"
+	ErrorNotSubscribed                  = "NOT_SUBSCRIBED"
+	ErrorInvalidAuthorizationCredential = "INVALID_AUTHORIZATION_CREDENTIAL"
+	ErrorExpiredAuthorizationCredential = "EXPIRED_AUTHORIZATION_CREDENTIAL"
+)
+
+// ErrUnsupported is returned by BaseHandler for directives the skill does not
+// implement. It is reported to Alexa as INVALID_DIRECTIVE.
+var ErrUnsupported = errors.New("video: directive not supported")
+
+// ErrDirectiveNil reports that the directive envelope was nil.
+var ErrDirectiveNil = errors.New("video: directive envelope was nil")
+
+// Error is returned by a Handler to send Alexa an ErrorResponse of Type.
+// Other errors are sent as INTERNAL_ERROR.
+type Error struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return "video: " + e.Type + ": " + e.Message
+}
+
+// DirectiveEnvelope contains the data passed from Alexa to a video skill.
+type DirectiveEnvelope struct {
+	Directive *Directive `json:"directive"`
+}
+
+// Directive is a request from Alexa. Payload is decoded by Skill according to
+// the Header.
+type Directive struct {
+	Header   Header          `json:"header"`
+	Endpoint *Endpoint       `json:"endpoint,omitempty"`
+	Payload  json.RawMessage `json:"payload"`
+}
+
+// Header identifies a directive or event.
+type Header struct {
+	Namespace        string `json:"namespace"`
+	Name             string `json:"name"`
+	MessageID        string `json:"messageId"`
+	CorrelationToken string `json:"correlationToken,omitempty"`
+	PayloadVersion   string `json:"payloadVersion"`
+}
+
+// Endpoint is the device the directive targets. Scope holds the access token
+// of the linked account.
+type Endpoint struct {
+	Scope      Scope             `json:"scope"`
+	EndpointID string            `json:"endpointId,omitempty"`
+	Cookie     map[string]string `json:"cookie,omitempty"`
+}
+
+// Scope authorizes a directive.
+type Scope struct {
+	Type  string `json:"type"`
+	Token string `json:"token"`
+}
+
+// EventEnvelope contains the response to a directive.
+type EventEnvelope struct {
+	Event   Event         `json:"event"`
+	Context *EventContext `json:"context,omitempty"`
+}
+
+// Event is the response to a directive.
+type Event struct {
+	Header   Header      `json:"header"`
+	Endpoint *Endpoint   `json:"endpoint,omitempty"`
+	Payload  interface{} `json:"payload"`
+}
+
+// EventContext reports the state of the endpoint after a directive.
+type EventContext struct {
+	Properties []Property `json:"properties"`
+}
+
+// Property is a reported state of the endpoint.
+type Property struct {
+	Namespace                 string      `json:"namespace"`
+	Name                      string      `json:"name"`
+	Value                     interface{} `json:"value"`
+	TimeOfSample              string      `json:"timeOfSample"`
+	UncertaintyInMilliseconds int         `json:"uncertaintyInMilliseconds"`
+}
+
+// Handler defines the interface that must be implemented to handle video
+// directives. Each method fills in the response passed to it. Embed
+// BaseHandler to implement only the directives the skill supports.
+type Handler interface {
+	OnGetPlayableItems(context.Context, *Directive, *ItemsRequest, *ItemsResponse) error
+	OnGetPlayableItemsMetadata(context.Context, *Directive, *GetPlayableItemsMetadataRequest, *GetPlayableItemsMetadataResponse) error
+	OnGetDisplayableItems(context.Context, *Directive, *ItemsRequest, *ItemsResponse) error
+	OnSearchAndPlay(context.Context, *Directive, *SearchRequest) error
+	OnSearchAndDisplayResults(context.Context, *Directive, *SearchRequest) error
+	OnChangeChannel(context.Context, *Directive, *ChangeChannelRequest, *ChannelResponse) error
+	OnSkipChannels(context.Context, *Directive, *SkipChannelsRequest, *ChannelResponse) error
+}
+
+// BaseHandler returns ErrUnsupported for every directive.
+type BaseHandler struct{}
+
+// OnGetPlayableItems returns ErrUnsupported.
+func (BaseHandler) OnGetPlayableItems(context.Context, *Directive, *ItemsRequest, *ItemsResponse) error {
+	return ErrUnsupported
+}
+
+// OnGetPlayableItemsMetadata returns ErrUnsupported.
+func (BaseHandler) OnGetPlayableItemsMetadata(context.Context, *Directive, *GetPlayableItemsMetadataRequest, *GetPlayableItemsMetadataResponse) error {
+	return ErrUnsupported
+}
+
+// OnGetDisplayableItems returns ErrUnsupported.
+func (BaseHandler) OnGetDisplayableItems(context.Context, *Directive, *ItemsRequest, *ItemsResponse) error {
+	return ErrUnsupported
+}
+
+// OnSearchAndPlay returns ErrUnsupported.
+func (BaseHandler) OnSearchAndPlay(context.Context, *Directive, *SearchRequest) error {
+	return ErrUnsupported
+}
+
+// OnSearchAndDisplayResults returns ErrUnsupported.
+func (BaseHandler) OnSearchAndDisplayResults(context.Context, *Directive, *SearchRequest) error {
+	return ErrUnsupported
+}
+
+// OnChangeChannel returns ErrUnsupported.
+func (BaseHandler) OnChangeChannel(context.Context, *Directive, *ChangeChannelRequest, *ChannelResponse) error {
+	return ErrUnsupported
+}
+
+// OnSkipChannels returns ErrUnsupported.
+func (BaseHandler) OnSkipChannels(context.Context, *Directive, *SkipChannelsRequest, *ChannelResponse) error {
+	return ErrUnsupported
+}
+
+// Skill is the video counterpart of alexa.Alexa.
+type Skill struct {
+	Handler Handler
+	// Custom, if set, handles requests that are not video directives, so a
+	// custom skill can share the Lambda function.
+	Custom *alexa.Alexa
+}
+
+// ProcessDirective handles a directive passed from Alexa and returns the
+// event to send back. Errors from the Handler are returned to Alexa as an
+// ErrorResponse event rather than as an error.
+func (s *Skill) ProcessDirective(ctx context.Context, directiveEnv *DirectiveEnvelope) (*EventEnvelope, error) {
+	if directiveEnv == nil || directiveEnv.Directive == nil {
+		return nil, ErrDirectiveNil
+	}
+	d := directiveEnv.Directive
+
+	var err error
+	var payload interface{} = struct{}{}
+	var channel *ChannelResponse
+	responseNamespace, responseName := "Alexa", "Response"
+
+	switch d.Header.Namespace + "." + d.Header.Name {
+	case NamespaceVideoContentProvider + "." + GetPlayableItems:
+		req, resp := &ItemsRequest{}, &ItemsResponse{MediaItems: []MediaItem{}}
+		if err = decode(d, req); err == nil {
+			err = s.Handler.OnGetPlayableItems(ctx, d, req, resp)
+		}
+		payload = resp
+		responseNamespace, responseName = NamespaceVideoContentProvider, GetPlayableItems+"Response"
+	case NamespaceVideoContentProvider + "." + GetPlayableItemsMetadata:
+		req, resp := &GetPlayableItemsMetadataRequest{}, &GetPlayableItemsMetadataResponse{SearchResults: []PlayableItemMetadata{}}
+		if err = decode(d, req); err == nil {
+			err = s.Handler.OnGetPlayableItemsMetadata(ctx, d, req, resp)
+		}
+		payload = resp
+		responseNamespace, responseName = NamespaceVideoContentProvider, GetPlayableItemsMetadata+"Response"
+	case NamespaceVideoContentProvider + "." + GetDisplayableItems:
+		req, resp := &ItemsRequest{}, &ItemsResponse{MediaItems: []MediaItem{}}
+		if err = decode(d, req); err == nil {
+			err = s.Handler.OnGetDisplayableItems(ctx, d, req, resp)
+		}
+		payload = resp
+		responseNamespace, responseName = NamespaceVideoContentProvider, GetDisplayableItems+"Response"
+	case NamespaceRemoteVideoPlayer + "." + SearchAndPlay:
+		req := &SearchRequest{}
+		if err = decode(d, req); err == nil {
+			err = s.Handler.OnSearchAndPlay(ctx, d, req)
+		}
+	case NamespaceRemoteVideoPlayer + "." + SearchAndDisplayResults:
+		req := &SearchRequest{}
+		if err = decode(d, req); err == nil {
+			err = s.Handler.OnSearchAndDisplayResults(ctx, d, req)
+		}
+	case NamespaceChannelController + "." + ChangeChannel:
+		req := &ChangeChannelRequest{}
+		channel = &ChannelResponse{}
+		if err = decode(d, req); err == nil {
+			err = s.Handler.OnChangeChannel(ctx, d, req, channel)
+		}
+	case NamespaceChannelController + "." + SkipChannels:
+		req := &SkipChannelsRequest{}
+		channel = &ChannelResponse{}
+		if err = decode(d, req); err == nil {
+			err = s.Handler.OnSkipChannels(ctx, d, req, channel)
+		}
+	default:
+		err = ErrUnsupported
+	}
+
+	if err != nil {
+		log.Println("Error handling "+d.Header.Namespace+"."+d.Header.Name+".", err.Error())
+		return errorEvent(d, err), nil
+	}
+
+	eventEnv := &EventEnvelope{Event: Event{
+		Header:   responseHeader(d, responseNamespace, responseName),
+		Endpoint: d.Endpoint,
+		Payload:  payload,
+	}}
+	if channel != nil {
+		eventEnv.Context = &EventContext{Properties: []Property{{
+			Namespace:    NamespaceChannelController,
+			Name:         "channel",
+			Value:        channel.Channel,
+			TimeOfSample: time.Now().UTC().Format(time.RFC3339),
+		}}}
+	}
+	return eventEnv, nil
+}
+
+// Invoke handles a raw Lambda payload. Video directives are handled by
+// ProcessDirective, anything else by Custom. It implements the Handler
+// interface of the aws-lambda-go lambda package.
+func (s *Skill) Invoke(ctx context.Context, payload []byte) ([]byte, error) {
+	var probe struct {
+		Directive json.RawMessage `json:"directive"`
+	}
+	if err := json.Unmarshal(payload, &probe); err != nil {
+		return nil, err
+	}
+
+	if probe.Directive == nil && s.Custom != nil {
+		requestEnv := &alexa.RequestEnvelope{}
+		if err := json.Unmarshal(payload, requestEnv); err != nil {
+			return nil, err
+		}
+		responseEnv, err := s.Custom.ProcessRequest(ctx, requestEnv)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(responseEnv)
+	}
+
+	directiveEnv := &DirectiveEnvelope{}
+	if err := json.Unmarshal(payload, directiveEnv); err != nil {
+		return nil, err
+	}
+	eventEnv, err := s.ProcessDirective(ctx, directiveEnv)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(eventEnv)
+}
+
+func decode(d *Directive, v interface{}) error {
+	if len(d.Payload) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(d.Payload, v); err != nil {
+		return &Error{Type: ErrorInvalidValue, Message: err.Error()}
+	}
+	return nil
+}
+
+func errorEvent(d *Directive, err error) *EventEnvelope {
+	e := &Error{Type: ErrorInternal, Message: err.Error()}
+	var target *Error
+	if errors.As(err, &target) {
+		e = target
+	} else if errors.Is(err, ErrUnsupported) {
+		e.Type = ErrorInvalidDirective
+	}
+	return &EventEnvelope{Event: Event{
+		Header:   responseHeader(d, "Alexa", "ErrorResponse"),
+		Endpoint: d.Endpoint,
+		Payload:  e,
+	}}
+}
+
+func responseHeader(d *Directive, namespace, name string) Header {
+	return Header{
+		Namespace:        namespace,
+		Name:             name,
+		MessageID:        uuid.New(),
+		CorrelationToken: d.Header.CorrelationToken,
+		PayloadVersion:   payloadVersion,
+	}
+}
@@ -0,0 +1,156 @@
+package video
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	alexa "github.com/ericdaugherty/alexa-skills-kit-golang"
+)
+
+const getPlayableItemsString = `{
+  "directive": {
+    "header": {
+      "namespace": "Alexa.VideoContentProvider",
+      "name": "GetPlayableItems",
+      "messageId": "msg-1",
+      "correlationToken": "corr-1",
+      "payloadVersion": "3"
+    },
+    "endpoint": {"scope": {"type": "BearerToken", "token": "access-token"}, "endpointId": "videoDevice-001"},
+    "payload": {
+      "entities": [
+        {"type": "Video", "value": "Big Buck Bunny", "externalIds": {"ENTITY_ID": "0"}},
+        {"type": "Genre", "value": "Animation"}
+      ],
+      "contentType": "ON_DEMAND",
+      "locale": "en-US",
+      "minResultLimit": 1,
+      "maxResultLimit": 40
+    }
+  }
+}`
+
+const changeChannelString = `{
+  "directive": {
+    "header": {"namespace": "Alexa.ChannelController", "name": "ChangeChannel", "messageId": "msg-2", "correlationToken": "corr-2", "payloadVersion": "3"},
+    "endpoint": {"scope": {"type": "BearerToken", "token": "access-token"}, "endpointId": "videoDevice-001"},
+    "payload": {"channel": {"callSign": "PBS"}, "channelMetadata": {"name": "PBS"}}
+  }
+}`
+
+type catalogHandler struct {
+	BaseHandler
+	token string
+}
+
+func (h *catalogHandler) OnGetPlayableItems(ctx context.Context, d *Directive, req *ItemsRequest, resp *ItemsResponse) error {
+	h.token = d.Endpoint.Scope.Token
+	for _, e := range EntitiesOfType(req.Entities, EntityVideo) {
+		if e.Value == "Big Buck Bunny" {
+			resp.MediaItems = append(resp.MediaItems, MediaItem{MediaIdentifier{ID: "bbb"}})
+		}
+	}
+	return nil
+}
+
+func (h *catalogHandler) OnChangeChannel(ctx context.Context, d *Directive, req *ChangeChannelRequest, resp *ChannelResponse) error {
+	if req.Channel.CallSign != "PBS" {
+		return &Error{Type: ErrorInvalidValue, Message: "unknown channel"}
+	}
+	resp.Channel = Channel{Number: "9", CallSign: "PBS"}
+	return nil
+}
+
+func process(t *testing.T, s *Skill, payload string) map[string]interface{} {
+	b, err := s.Invoke(context.Background(), []byte(payload))
+	if err != nil {
+		t.Fatal("Error invoking skill. " + err.Error())
+	}
+	var v map[string]interface{}
+	json.Unmarshal(b, &v)
+	return v
+}
+
+func header(v map[string]interface{}) map[string]interface{} {
+	return v["event"].(map[string]interface{})["header"].(map[string]interface{})
+}
+
+func TestGetPlayableItems(t *testing.T) {
+	h := &catalogHandler{}
+	v := process(t, &Skill{Handler: h}, getPlayableItemsString)
+
+	if h.token != "access-token" {
+		t.Error("Expected endpoint scope to be passed to handler but was", h.token)
+	}
+	hdr := header(v)
+	if hdr["namespace"] != NamespaceVideoContentProvider || hdr["name"] != "GetPlayableItemsResponse" || hdr["correlationToken"] != "corr-1" {
+		t.Error("Expected GetPlayableItemsResponse header but was", hdr)
+	}
+	b, _ := json.Marshal(v["event"].(map[string]interface{})["payload"])
+	if string(b) != `{"mediaItems":[{"mediaIdentifier":{"id":"bbb"}}]}` {
+		t.Error("Expected media items but payload was", string(b))
+	}
+}
+
+func TestChangeChannel(t *testing.T) {
+	s := &Skill{Handler: &catalogHandler{}}
+	v := process(t, s, changeChannelString)
+	if hdr := header(v); hdr["namespace"] != "Alexa" || hdr["name"] != "Response" {
+		t.Error("Expected Alexa.Response header but was", hdr)
+	}
+	props := v["context"].(map[string]interface{})["properties"].([]interface{})
+	if props[0].(map[string]interface{})["value"].(map[string]interface{})["number"] != "9" {
+		t.Error("Expected channel to be reported in context but was", props)
+	}
+
+	v = process(t, s, strings.Replace(changeChannelString, `"callSign": "PBS"`, `"callSign": "XYZ"`, 1))
+	if hdr := header(v); hdr["name"] != "ErrorResponse" {
+		t.Error("Expected ErrorResponse but was", hdr)
+	}
+	payload := v["event"].(map[string]interface{})["payload"].(map[string]interface{})
+	if payload["type"] != ErrorInvalidValue {
+		t.Error("Expected INVALID_VALUE but was", payload)
+	}
+}
+
+func TestUnsupportedDirective(t *testing.T) {
+	s := &Skill{Handler: &catalogHandler{}}
+	v := process(t, s, strings.Replace(changeChannelString, `"ChangeChannel"`, `"SkipChannels"`, 1))
+	payload := v["event"].(map[string]interface{})["payload"].(map[string]interface{})
+	if payload["type"] != ErrorInvalidDirective {
+		t.Error("Expected INVALID_DIRECTIVE for directive not implemented but was", payload)
+	}
+}
+
+type customHandler struct{}
+
+func (h *customHandler) OnSessionStarted(context.Context, *alexa.Request, *alexa.Session, *alexa.Context, *alexa.Response) error {
+	return nil
+}
+
+func (h *customHandler) OnLaunch(ctx context.Context, request *alexa.Request, session *alexa.Session, aContext *alexa.Context, response *alexa.Response) error {
+	response.SetOutputText("Welcome")
+	return nil
+}
+
+func (h *customHandler) OnIntent(context.Context, *alexa.Request, *alexa.Session, *alexa.Context, *alexa.Response) error {
+	return nil
+}
+
+func (h *customHandler) OnSessionEnded(context.Context, *alexa.Request, *alexa.Session, *alexa.Context, *alexa.Response) error {
+	return nil
+}
+
+func TestInvokeCustomRequest(t *testing.T) {
+	s := &Skill{
+		Handler: &catalogHandler{},
+		Custom:  &alexa.Alexa{RequestHandler: &customHandler{}, IgnoreApplicationID: true, IgnoreTimestamp: true},
+	}
+	v := process(t, s, `{"version": "1.0", "session": {"new": false}, "request": {"type": "LaunchRequest"}}`)
+	speech := v["response"].(map[string]interface{})["outputSpeech"].(map[string]interface{})
+	if speech["text"] != "Welcome" {
+		t.Error("Expected custom request to be handled by Custom but response was", v)
+	}
+}
@@ -0,0 +1,454 @@
+// Package webhook maps intents to outbound HTTP webhook calls from a
+// configuration file, for voice triggers that need no Go code, such as
+// "Alexa, ask ops to restart staging".
+//
+// Each Hook matches an intent, and optionally slot values, and calls a URL
+// with a body built from a template. The request is signed with HMAC-SHA256
+// when the hook has a secret, and the response of the webhook is spoken
+// through another template:
+//
+//	{
+//	  "timeout": "5s",
+//	  "hooks": [{
+//	    "intent": "RestartIntent",
+//	    "slots": {"Environment": "staging"},
+//	    "url": "https://ops.example.com/restart",
+//	    "body": "{\"environment\": {{json .Slots.Environment}}}",
+//	    "secretEnv": "OPS_WEBHOOK_SECRET",
+//	    "speech": "Restarting {{.Slots.Environment}}. {{get .Response \"message\"}}",
+//	    "errorSpeech": "Sorry, I couldn't restart {{.Slots.Environment}}."
+//	  }]
+//	}
+//
+// Templates use text/template. Body and speech templates get Data, and the
+// speech templates also the Status, Body and decoded Response of the webhook.
+// A missing map key fails the template; use get for optional fields, as in
+// {{get .Response "message"}}. When the speech template fails, errorSpeech is
+// spoken, and if that fails too, DefaultErrorSpeech.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	alexa "github.com/ericdaugherty/alexa-skills-kit-golang"
+)
+
+// Headers of signed requests.
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	TimestampHeader = "X-Webhook-Timestamp"
+)
+
+// DefaultTimeout bounds webhook calls without a timeout. Alexa waits for about
+// eight seconds in all.
+const DefaultTimeout = 5 * time.Second
+
+// DefaultErrorSpeech is spoken when a webhook fails and the hook has no
+// errorSpeech.
+const DefaultErrorSpeech = "Sorry, that didn't work."
+
+// maxResponseSize limits the webhook response read.
+const maxResponseSize = 1 << 20
+
+var (
+	// ErrInvalidConfig is matched by errors.Is for configuration errors.
+	ErrInvalidConfig = errors.New("webhook: invalid configuration")
+	// ErrWebhookFailed reports a webhook response that is not 2xx.
+	ErrWebhookFailed = errors.New("webhook: call failed")
+)
+
+// Duration is a time.Duration written in JSON as a string, such as "5s".
+type Duration time.Duration
+
+// UnmarshalJSON parses a duration string.
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// MarshalJSON writes the duration as a string.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// Config is the configuration of a Handler.
+type Config struct {
+	// Timeout of hooks without one. Default value is DefaultTimeout.
+	Timeout Duration `json:"timeout,omitempty"`
+	// LaunchSpeech is spoken on launch, keeping the session open.
+	LaunchSpeech string `json:"launchSpeech,omitempty"`
+	// UnhandledSpeech is spoken for intents no hook matches. If empty, they
+	// fail with alexa.ErrUnhandledIntent.
+	UnhandledSpeech string `json:"unhandledSpeech,omitempty"`
+	Hooks           []Hook `json:"hooks"`
+}
+
+// Hook calls a webhook for an intent.
+type Hook struct {
+	Intent string `json:"intent"`
+	// Slots the intent must have, by name and value. Values are compared
+	// without case, with the value resolved by entity resolution if any.
+	Slots map[string]string `json:"slots,omitempty"`
+	URL   string            `json:"url"`
+	// Method defaults to POST.
+	Method  string            `json:"method,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	// Body is a template for the JSON body. Use the json function to quote
+	// values.
+	Body string `json:"body,omitempty"`
+	// SecretEnv names the environment variable holding the HMAC secret.
+	SecretEnv   string   `json:"secretEnv,omitempty"`
+	Timeout     Duration `json:"timeout,omitempty"`
+	Speech      string   `json:"speech"`
+	ErrorSpeech string   `json:"errorSpeech,omitempty"`
+}
+
+// Data is passed to the templates of a Hook.
+type Data struct {
+	Intent    string
+	Slots     map[string]string
+	UserID    string
+	Locale    string
+	RequestID string
+	// Status, Body and Response are set for speech templates once the
+	// webhook responds. Response is the decoded body if it is a JSON object,
+	// and otherwise empty.
+	Status   int
+	Body     string
+	Response map[string]interface{}
+	// Error is set for errorSpeech.
+	Error string
+}
+
+var funcs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	// get returns the key of m, or "" if m is not a map or has no key.
+	"get": func(m interface{}, key string) interface{} {
+		values, _ := m.(map[string]interface{})
+		if v, ok := values[key]; ok && v != nil {
+			return v
+		}
+		return ""
+	},
+}
+
+// Sign returns the signature of a request sent at timestamp with body:
+// sha256= followed by the hex HMAC-SHA256, keyed with secret, of the
+// timestamp, a dot and the body.
+func Sign(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature of a request received by a webhook, and that
+// it was sent within tolerance of the current time, so that a captured
+// request cannot be replayed later. It is the counterpart of Sign, for
+// webhooks written in Go.
+func Verify(secret []byte, r *http.Request, body []byte, tolerance time.Duration) bool {
+	timestamp := r.Header.Get(TimestampHeader)
+	sent, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	if age := time.Since(time.Unix(sent, 0)); age > tolerance || age < -tolerance {
+		return false
+	}
+	expected := Sign(secret, timestamp, body)
+	return hmac.Equal([]byte(expected), []byte(r.Header.Get(SignatureHeader)))
+}
+
+// hook is a Hook with its templates parsed.
+type hook struct {
+	Hook
+	secret      []byte
+	timeout     time.Duration
+	body        *template.Template
+	speech      *template.Template
+	errorSpeech *template.Template
+}
+
+// Handler is an alexa.RequestHandler calling the webhook of the first Hook
+// matching each intent.
+type Handler struct {
+	HTTPClient *http.Client
+	Now        func() time.Time
+
+	config Config
+	hooks  []*hook
+}
+
+// Load reads the configuration at path and creates a Handler.
+func Load(path string) (*Handler, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	config := Config{}
+	if err := json.Unmarshal(b, &config); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidConfig, path, err)
+	}
+	return New(config)
+}
+
+// New creates a Handler for config. Templates are parsed, and secrets read
+// from the environment, once.
+func New(config Config) (*Handler, error) {
+	h := &Handler{config: config}
+	var problems []string
+	for i, c := range config.Hooks {
+		hk, err := compile(c, time.Duration(config.Timeout))
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("hook %d (%s): %v", i, c.Intent, err))
+			continue
+		}
+		h.hooks = append(h.hooks, hk)
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidConfig, strings.Join(problems, "; "))
+	}
+	return h, nil
+}
+
+func compile(c Hook, timeout time.Duration) (*hook, error) {
+	if c.Intent == "" {
+		return nil, errors.New("no intent")
+	}
+	u, err := url.Parse(c.URL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return nil, fmt.Errorf("invalid url %q", c.URL)
+	}
+	if c.Method == "" {
+		c.Method = http.MethodPost
+	}
+	hk := &hook{Hook: c, timeout: time.Duration(c.Timeout)}
+	if hk.timeout == 0 {
+		hk.timeout = timeout
+	}
+	if hk.timeout == 0 {
+		hk.timeout = DefaultTimeout
+	}
+	if c.SecretEnv != "" {
+		secret := os.Getenv(c.SecretEnv)
+		if secret == "" {
+			return nil, fmt.Errorf("secret %s is not set", c.SecretEnv)
+		}
+		hk.secret = []byte(secret)
+	}
+	if c.ErrorSpeech == "" {
+		c.ErrorSpeech = DefaultErrorSpeech
+	}
+	for _, t := range []struct {
+		name string
+		text string
+		tmpl **template.Template
+	}{
+		{"body", c.Body, &hk.body},
+		{"speech", c.Speech, &hk.speech},
+		{"errorSpeech", c.ErrorSpeech, &hk.errorSpeech},
+	} {
+		*t.tmpl, err = template.New(t.name).Funcs(funcs).Option("missingkey=error").Parse(t.text)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return hk, nil
+}
+
+// OnSessionStarted does nothing.
+func (h *Handler) OnSessionStarted(ctx context.Context, request *alexa.Request, session *alexa.Session, aContext *alexa.Context, response *alexa.Response) error {
+	return nil
+}
+
+// OnLaunch speaks LaunchSpeech, if set.
+func (h *Handler) OnLaunch(ctx context.Context, request *alexa.Request, session *alexa.Session, aContext *alexa.Context, response *alexa.Response) error {
+	if h.config.LaunchSpeech != "" {
+		response.SetOutputText(h.config.LaunchSpeech)
+		response.SetRepromptText(h.config.LaunchSpeech)
+		response.ShouldSessionEnd = false
+	}
+	return nil
+}
+
+// OnIntent calls the webhook of the first Hook matching the intent, and
+// speaks its speech, or its errorSpeech if the call fails.
+func (h *Handler) OnIntent(ctx context.Context, request *alexa.Request, session *alexa.Session, aContext *alexa.Context, response *alexa.Response) error {
+	data := &Data{
+		Intent:    request.Intent.Name,
+		Slots:     slotValues(request.Intent),
+		UserID:    session.User.UserID,
+		Locale:    request.Locale,
+		RequestID: request.RequestID,
+		Response:  make(map[string]interface{}),
+	}
+	hk := h.match(data)
+	if hk == nil {
+		if h.config.UnhandledSpeech == "" {
+			return alexa.ErrUnhandledIntent
+		}
+		response.SetOutputText(h.config.UnhandledSpeech)
+		return nil
+	}
+
+	text, err := "", h.call(ctx, hk, data)
+	if err == nil {
+		if text, err = render(hk.speech, data); err != nil {
+			log.Println("Error rendering speech for", data.Intent+".", err)
+		}
+	} else {
+		log.Println("Error calling webhook for", data.Intent+".", err)
+	}
+	if err != nil {
+		data.Error = err.Error()
+		if text, err = render(hk.errorSpeech, data); err != nil {
+			log.Println("Error rendering errorSpeech for", data.Intent+".", err)
+			text = DefaultErrorSpeech
+		}
+	}
+	response.SetOutputText(text)
+	return nil
+}
+
+func render(t *template.Template, data *Data) (string, error) {
+	var b bytes.Buffer
+	if err := t.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// OnSessionEnded does nothing.
+func (h *Handler) OnSessionEnded(ctx context.Context, request *alexa.Request, session *alexa.Session, aContext *alexa.Context, response *alexa.Response) error {
+	return nil
+}
+
+// match returns the first hook matching data.
+func (h *Handler) match(data *Data) *hook {
+	for _, hk := range h.hooks {
+		if hk.Intent != data.Intent {
+			continue
+		}
+		matched := true
+		for name, value := range hk.Slots {
+			if !strings.EqualFold(data.Slots[name], value) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return hk
+		}
+	}
+	return nil
+}
+
+// call sends the request of hk and sets the response in data.
+func (h *Handler) call(ctx context.Context, hk *hook, data *Data) error {
+	var body bytes.Buffer
+	if err := hk.body.Execute(&body, data); err != nil {
+		return err
+	}
+	if body.Len() > 0 && !json.Valid(body.Bytes()) {
+		return errors.New("webhook: body template did not produce JSON")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, hk.timeout)
+	defer cancel()
+	var reader io.Reader
+	if body.Len() > 0 {
+		reader = bytes.NewReader(body.Bytes())
+	}
+	req, err := http.NewRequestWithContext(ctx, hk.Method, hk.URL, reader)
+	if err != nil {
+		return err
+	}
+	if body.Len() > 0 {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for k, v := range hk.Headers {
+		req.Header.Set(k, v)
+	}
+	if hk.secret != nil {
+		timestamp := strconv.FormatInt(h.now().Unix(), 10)
+		req.Header.Set(TimestampHeader, timestamp)
+		req.Header.Set(SignatureHeader, Sign(hk.secret, timestamp, body.Bytes()))
+	}
+
+	client := h.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return err
+	}
+
+	data.Status = resp.StatusCode
+	data.Body = string(b)
+	if json.Valid(b) {
+		json.Unmarshal(b, &data.Response)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%w: %s", ErrWebhookFailed, resp.Status)
+	}
+	return nil
+}
+
+func (h *Handler) now() time.Time {
+	if h.Now == nil {
+		return time.Now()
+	}
+	return h.Now()
+}
+
+// slotValues returns the value of each slot of intent, resolved by entity
+// resolution if it matched.
+func slotValues(intent alexa.Intent) map[string]string {
+	values := make(map[string]string, len(intent.Slots))
+	for name, slot := range intent.Slots {
+		values[name] = slot.Value
+		if slot.Resolutions == nil {
+			continue
+		}
+		for _, r := range slot.Resolutions.ResolutionsPerAuthority {
+			if r.Status.Code == "ER_SUCCESS_MATCH" && len(r.Values) > 0 {
+				values[name] = r.Values[0].Value.Name
+				break
+			}
+		}
+	}
+	return values
+}
@@ -0,0 +1,204 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	alexa "github.com/ericdaugherty/alexa-skills-kit-golang"
+)
+
+// receiver is a local webhook verifying signatures.
+type receiver struct {
+	secret []byte
+	bodies []map[string]interface{}
+	delay  time.Duration
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	if !Verify(rc.secret, r, body, time.Minute) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	var v map[string]interface{}
+	json.Unmarshal(body, &v)
+	rc.bodies = append(rc.bodies, v)
+	if rc.delay > 0 {
+		time.Sleep(rc.delay)
+	}
+	if v["environment"] == "production" {
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(`{"message":"Production is locked."}`))
+		return
+	}
+	w.Write([]byte(`{"message":"It will be back in two minutes."}`))
+}
+
+func restartRequest(environment string) *alexa.RequestEnvelope {
+	requestEnv := &alexa.RequestEnvelope{
+		Session: &alexa.Session{New: true},
+		Request: &alexa.Request{Type: "IntentRequest", Locale: "en-US", Intent: alexa.Intent{
+			Name:  "RestartIntent",
+			Slots: map[string]alexa.IntentSlot{"Environment": {Name: "Environment", Value: environment}},
+		}},
+	}
+	requestEnv.Session.User.UserID = "amzn1.ask.account.1"
+	return requestEnv
+}
+
+func newHandler(t *testing.T, url string) *Handler {
+	t.Setenv("OPS_WEBHOOK_SECRET", "s3cret")
+	config := `{
+		"timeout": "200ms",
+		"unhandledSpeech": "I can't restart that.",
+		"hooks": [{
+			"intent": "RestartIntent",
+			"slots": {"Environment": "staging"},
+			"url": "` + url + `",
+			"body": "{\"environment\": {{json .Slots.Environment}}, \"user\": {{json .UserID}}}",
+			"secretEnv": "OPS_WEBHOOK_SECRET",
+			"speech": "Restarting {{.Slots.Environment}}. {{.Response.message}}"
+		}, {
+			"intent": "RestartIntent",
+			"slots": {"Environment": "production"},
+			"url": "` + url + `",
+			"body": "{\"environment\": \"production\"}",
+			"secretEnv": "OPS_WEBHOOK_SECRET",
+			"speech": "Restarting production.",
+			"errorSpeech": "Sorry. {{.Response.message}}"
+		}]
+	}`
+	path := filepath.Join(t.TempDir(), "webhooks.json")
+	os.WriteFile(path, []byte(config), 0644)
+	h, err := Load(path)
+	if err != nil {
+		t.Fatal("Error loading config. " + err.Error())
+	}
+	return h
+}
+
+func say(t *testing.T, h *Handler, environment string) string {
+	a := &alexa.Alexa{RequestHandler: h, IgnoreApplicationID: true, IgnoreTimestamp: true}
+	responseEnv, err := a.ProcessRequest(context.Background(), restartRequest(environment))
+	if err != nil {
+		t.Fatal("Error processing request. " + err.Error())
+	}
+	return responseEnv.Response.OutputSpeech.Text
+}
+
+func TestHandler(t *testing.T) {
+	rc := &receiver{secret: []byte("s3cret")}
+	server := httptest.NewServer(rc)
+	defer server.Close()
+	h := newHandler(t, server.URL)
+
+	if text := say(t, h, "Staging"); text != "Restarting Staging. It will be back in two minutes." {
+		t.Error("Expected the webhook response to be spoken but was", text)
+	}
+	if len(rc.bodies) != 1 || rc.bodies[0]["environment"] != "Staging" || rc.bodies[0]["user"] != "amzn1.ask.account.1" {
+		t.Error("Expected a signed body with the slot and user but was", rc.bodies)
+	}
+
+	if text := say(t, h, "production"); text != "Sorry. Production is locked." {
+		t.Error("Expected the error speech but was", text)
+	}
+	if text := say(t, h, "dev"); text != "I can't restart that." {
+		t.Error("Expected no hook to match but speech was", text)
+	}
+}
+
+func TestHandlerStaleTimestamp(t *testing.T) {
+	rc := &receiver{secret: []byte("s3cret")}
+	server := httptest.NewServer(rc)
+	defer server.Close()
+	h := newHandler(t, server.URL)
+	h.Now = func() time.Time { return time.Now().Add(-time.Hour) }
+
+	if text := say(t, h, "Staging"); text == "Restarting Staging. It will be back in two minutes." {
+		t.Error("Expected a request signed an hour ago to be rejected but speech was", text)
+	}
+	if len(rc.bodies) != 0 {
+		t.Error("Expected no body to be accepted but was", rc.bodies)
+	}
+}
+
+func TestHandlerTimeout(t *testing.T) {
+	rc := &receiver{secret: []byte("s3cret"), delay: 500 * time.Millisecond}
+	server := httptest.NewServer(rc)
+	defer server.Close()
+	h := newHandler(t, server.URL)
+
+	start := time.Now()
+	if text := say(t, h, "staging"); text != DefaultErrorSpeech {
+		t.Error("Expected the default error speech but was", text)
+	}
+	if time.Since(start) > 450*time.Millisecond {
+		t.Error("Expected the call to time out but it took", time.Since(start))
+	}
+}
+
+func TestHandlerResponseNotJSON(t *testing.T) {
+	var status int
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+	h, err := New(Config{Hooks: []Hook{{
+		Intent:      "RestartIntent",
+		Slots:       map[string]string{"Environment": "production"},
+		URL:         server.URL,
+		Speech:      "Restarting. {{.Response.message}}",
+		ErrorSpeech: "Sorry, production didn't say.",
+	}, {
+		Intent:      "RestartIntent",
+		URL:         server.URL,
+		Speech:      `Restarting. {{get .Response "message"}}`,
+		ErrorSpeech: "Sorry. {{.Response.message}}",
+	}}})
+	if err != nil {
+		t.Fatal("Error creating handler. " + err.Error())
+	}
+
+	status, body = http.StatusNoContent, ""
+	if text := say(t, h, "staging"); text != "Restarting." {
+		t.Error("Expected no message for a 204 reply but was", text)
+	}
+	status, body = http.StatusOK, "OK"
+	if text := say(t, h, "staging"); text != "Restarting." {
+		t.Error("Expected no message for a reply that is not JSON but was", text)
+	}
+	if text := say(t, h, "production"); text != "Sorry, production didn't say." {
+		t.Error("Expected the error speech for a failing speech template but was", text)
+	}
+	status, body = http.StatusBadGateway, "<html>Bad Gateway</html>"
+	if text := say(t, h, "staging"); text != DefaultErrorSpeech {
+		t.Error("Expected the default error speech for a failing errorSpeech but was", text)
+	}
+}
+
+func TestInvalidConfig(t *testing.T) {
+	_, err := New(Config{Hooks: []Hook{
+		{Intent: "A", URL: "ftp://example.com"},
+		{Intent: "B", URL: "https://example.com", Speech: "{{.Missing"},
+		{Intent: "C", URL: "https://example.com", SecretEnv: "WEBHOOK_TEST_UNSET"},
+	}})
+	if !errors.Is(err, ErrInvalidConfig) {
+		t.Fatal("Expected ErrInvalidConfig but got", err)
+	}
+	for _, want := range []string{"(A): invalid url", "(B): template", "(C): secret WEBHOOK_TEST_UNSET"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Error("Expected error to contain", want, "but was", err)
+		}
+	}
+}